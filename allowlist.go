@@ -0,0 +1,90 @@
+package helmcharts
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// RegistryAllowList restricts the images of a chart to trusted registries and
+// repositories. Image fields are the fields tagged image:"true": strings hold a
+// full reference (nginx:1.27) and structs such as Image hold it in their
+// Repository field.
+type RegistryAllowList struct {
+	patterns []string
+}
+
+// NewRegistryAllowList returns an allow-list of glob patterns matched against
+// the normalised registry/repository of images, e.g. ghcr.io/tacokumo/* or
+// docker.io/library/caddy. As in path.Match, * does not match a slash; a
+// trailing /** matches any number of path components.
+func NewRegistryAllowList(patterns ...string) (*RegistryAllowList, error) {
+	for _, p := range patterns {
+		if p == "" {
+			return nil, fmt.Errorf("empty registry pattern")
+		}
+		if _, err := path.Match(strings.TrimSuffix(p, "/**"), ""); err != nil {
+			return nil, fmt.Errorf("invalid registry pattern %q: %w", p, err)
+		}
+	}
+	return &RegistryAllowList{patterns: patterns}, nil
+}
+
+// Allows reports whether ref is from an allowed registry and repository
+func (a *RegistryAllowList) Allows(ref ImageReference) bool {
+	name := ref.Name()
+	for _, p := range a.patterns {
+		if prefix, ok := strings.CutSuffix(p, "/**"); ok {
+			if matchPathPrefix(prefix, name) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathPrefix reports whether the leading path components of name match pattern
+func matchPathPrefix(pattern, name string) bool {
+	n := strings.Count(pattern, "/") + 1
+	parts := strings.SplitN(name, "/", n+1)
+	if len(parts) <= n {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.Join(parts[:n], "/"))
+	return ok
+}
+
+// Check validates every image field of values, a chart's *Values, against the
+// allow-list. Malformed references are left to Validate.
+func (a *RegistryAllowList) Check(values any) error {
+	var errs Errors
+	walkValues("", reflect.ValueOf(values), "", func(field string, v reflect.Value, tag reflect.StructTag) bool {
+		if tag.Get("image") != "true" {
+			return false
+		}
+		switch v.Kind() {
+		case reflect.String:
+			a.checkReference(&errs, field, v.String())
+		case reflect.Struct:
+			if repo := v.FieldByName("Repository"); repo.IsValid() && repo.Kind() == reflect.String {
+				a.checkReference(&errs, joinField(field, "Repository"), repo.String())
+				return true
+			}
+		}
+		return false
+	})
+	return errs.Err()
+}
+
+func (a *RegistryAllowList) checkReference(errs *Errors, field, reference string) {
+	ref, err := ParseImageReference(reference)
+	if err != nil || a.Allows(ref) {
+		return
+	}
+	errs.Add(field, "registry_allowlist", fmt.Sprintf("image %s is not from an allowed registry; allowed are %s", ref.Name(), strings.Join(a.patterns, ", ")))
+}
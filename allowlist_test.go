@@ -0,0 +1,104 @@
+package helmcharts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryAllowListAllows(t *testing.T) {
+	allowList, err := NewRegistryAllowList("ghcr.io/tacokumo/*", "mirror.example.com:5000/**", "docker.io/library/caddy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "ghcr.io/tacokumo/portal-api:1.0", want: true},
+		{ref: "ghcr.io/tacokumo/team/portal-api:1.0", want: false},
+		{ref: "ghcr.io/other/portal-api:1.0", want: false},
+		{ref: "mirror.example.com:5000/library/nginx", want: true},
+		{ref: "mirror.example.com:5000/a/b/c:1.0", want: true},
+		{ref: "mirror.example.com/nginx", want: false},
+		{ref: "caddy:2.11", want: true},
+		{ref: "docker.io/library/caddy", want: true},
+		{ref: "nginx:1.27", want: false},
+		{ref: "bitnami/caddy", want: false},
+	}
+
+	for _, tt := range tests {
+		ref, err := ParseImageReference(tt.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := allowList.Allows(ref); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestNewRegistryAllowListErrors(t *testing.T) {
+	for _, pattern := range []string{"", "ghcr.io/[tacokumo", "mirror.example.com/[/**"} {
+		if _, err := NewRegistryAllowList(pattern); err == nil {
+			t.Errorf("NewRegistryAllowList(%q) should fail", pattern)
+		}
+	}
+}
+
+// allowListTestValues mirrors the image fields of the charts
+type allowListTestValues struct {
+	Main struct {
+		Image string `yaml:"image" image:"true"`
+		Name  string `yaml:"name"`
+	} `yaml:"main"`
+	API struct {
+		Image Image `yaml:"image" image:"true"`
+	} `yaml:"api"`
+	Sidecars []struct {
+		Image *Image `yaml:"image" image:"true"`
+	} `yaml:"sidecars"`
+}
+
+func (v *allowListTestValues) Validate() error { return nil }
+
+func TestRegistryAllowListCheck(t *testing.T) {
+	allowList, err := NewRegistryAllowList("ghcr.io/tacokumo/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := mustParseDocument(t, "values.yaml", `main:
+  image: nginx:1.27
+  name: docker.io/not-an-image-field
+api:
+  image:
+    repository: ghcr.io/tacokumo/portal-api
+    tag: "1.0"
+sidecars:
+  - image:
+      repository: ghcr.io/tacokumo/proxy
+      tag: "1.0"
+  - image:
+      repository: quay.io/prometheus/node-exporter
+      tag: v1.8.0
+  - {}
+`)
+	var values allowListTestValues
+	report, err := validateDocuments(&values, []*Document{doc}, allowList.Check)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"values.yaml:2:3: main.image: image docker.io/library/nginx is not from an allowed registry; allowed are ghcr.io/tacokumo/*",
+		"values.yaml:13:7: sidecars[1].image.repository: image quay.io/prometheus/node-exporter is not from an allowed registry; allowed are ghcr.io/tacokumo/*",
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
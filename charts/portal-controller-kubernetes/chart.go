@@ -0,0 +1,24 @@
+package portal_controller_kubernetes
+
+import (
+	_ "embed"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+// ValuesYAML is the chart's values.yaml, the defaults Helm merges -f files onto
+//
+//go:embed values.yaml
+var ValuesYAML []byte
+
+//go:embed Chart.yaml
+var chartYAML []byte
+
+func init() {
+	helmcharts.MustRegister(helmcharts.Chart{
+		Dir:        "charts/portal-controller-kubernetes",
+		ChartYAML:  chartYAML,
+		ValuesYAML: ValuesYAML,
+		NewValues:  func() helmcharts.Validatable { return new(Values) },
+	})
+}
@@ -12,21 +12,21 @@ type Values struct {
 
 // CRDsConfig represents Custom Resource Definitions configuration
 type CRDsConfig struct {
-	Install bool `yaml:"install"`
+	Install *bool `yaml:"install" default:"true"`
 }
 
 // ControllerConfig represents the controller deployment configuration
 type ControllerConfig struct {
 	// Pod configuration
-	TerminationGracePeriodSeconds int64 `yaml:"terminationGracePeriodSeconds" validate:"min=0"`
+	TerminationGracePeriodSeconds int64 `yaml:"terminationGracePeriodSeconds" validate:"min=0" default:"10"`
 
 	// Kubernetes resource metadata
-	Affinity         *helmcharts.Affinity `yaml:"affinity,omitempty"`
-	Labels           map[string]string    `yaml:"labels,omitempty"`
-	Annotations      map[string]string    `yaml:"annotations,omitempty"`
-	PodAnnotations   map[string]string    `yaml:"podAnnotations,omitempty"`
-	PodLabels        map[string]string    `yaml:"podLabels,omitempty"`
-	ImagePullSecrets []ImagePullSecret    `yaml:"imagePullSecrets,omitempty" validate:"dive"`
+	Affinity         *helmcharts.Affinity `yaml:"affinity,omitempty" default:"{}"`
+	Labels           map[string]string    `yaml:"labels,omitempty" default:"{}"`
+	Annotations      map[string]string    `yaml:"annotations,omitempty" default:"{kubectl.kubernetes.io/default-container: manager}"`
+	PodAnnotations   map[string]string    `yaml:"podAnnotations,omitempty" default:"{}"`
+	PodLabels        map[string]string    `yaml:"podLabels,omitempty" default:"{app.kubernetes.io/component: controller}"`
+	ImagePullSecrets []ImagePullSecret    `yaml:"imagePullSecrets,omitempty" validate:"dive" default:"[]"`
 
 	// Security context for the pod
 	SecurityContext PodSecurityContext `yaml:"securityContext"`
@@ -55,20 +55,20 @@ type ControllerConfig struct {
 // ManagerContainerConfig represents the manager container configuration
 type ManagerContainerConfig struct {
 	// Container image
-	Image ContainerImage `yaml:"image" validate:"required"`
+	Image ContainerImage `yaml:"image" validate:"required" image:"true"`
 
 	// Command line arguments
-	ExtraArgs []string `yaml:"extraArgs,omitempty"`
+	ExtraArgs []string `yaml:"extraArgs,omitempty" default:"[]"`
 
 	// Health check probes
-	LivenessProbe  HTTPProbeConfig `yaml:"livenessProbe"`
-	ReadinessProbe HTTPProbeConfig `yaml:"readinessProbe"`
+	LivenessProbe  HTTPProbeConfig `yaml:"livenessProbe" default.httpGet.path:"/healthz" default.httpGet.port:"8081" default.initialDelaySeconds:"15" default.periodSeconds:"20"`
+	ReadinessProbe HTTPProbeConfig `yaml:"readinessProbe" default.httpGet.path:"/readyz" default.httpGet.port:"8081" default.initialDelaySeconds:"5" default.periodSeconds:"10"`
 
 	// Security context for the container
 	SecurityContext ContainerSecurityContext `yaml:"securityContext"`
 
 	// Resource limits and requests
-	Resources helmcharts.Resources `yaml:"resources"`
+	Resources helmcharts.Resources `yaml:"resources" default.limits.cpu:"500m" default.limits.memory:"128Mi" default.requests.cpu:"10m" default.requests.memory:"64Mi"`
 
 	// Environment variables
 	Env     []EnvVar        `yaml:"env,omitempty" validate:"dive"`
@@ -90,8 +90,8 @@ type ManagerContainerConfig struct {
 
 // ContainerImage represents container image configuration
 type ContainerImage struct {
-	Repository string `yaml:"repository" validate:"required"`
-	Tag        string `yaml:"tag" validate:"required"`
+	Repository string `yaml:"repository" validate:"required" default:"ghcr.io/tacokumo/portal-controller-kubernetes"`
+	Tag        string `yaml:"tag" validate:"required" default:"v0.8.0"`
 	PullPolicy string `yaml:"pullPolicy,omitempty" validate:"omitempty,oneof=Always IfNotPresent Never"`
 }
 
@@ -122,9 +122,9 @@ type HTTPHeader struct {
 
 // PodSecurityContext represents pod-level security context
 type PodSecurityContext struct {
-	RunAsUser           *int64          `yaml:"runAsUser,omitempty" validate:"omitempty,min=0"`
+	RunAsUser           *int64          `yaml:"runAsUser,omitempty" validate:"omitempty,min=0" default:"65532"`
 	RunAsGroup          *int64          `yaml:"runAsGroup,omitempty" validate:"omitempty,min=0"`
-	RunAsNonRoot        *bool           `yaml:"runAsNonRoot,omitempty"`
+	RunAsNonRoot        *bool           `yaml:"runAsNonRoot,omitempty" default:"true"`
 	FSGroup             *int64          `yaml:"fsGroup,omitempty" validate:"omitempty,min=0"`
 	FSGroupChangePolicy *string         `yaml:"fsGroupChangePolicy,omitempty" validate:"omitempty,oneof=Always OnRootMismatch"`
 	SeccompProfile      *SeccompProfile `yaml:"seccompProfile,omitempty"`
@@ -138,12 +138,12 @@ type PodSecurityContext struct {
 type ContainerSecurityContext struct {
 	RunAsUser                *int64          `yaml:"runAsUser,omitempty" validate:"omitempty,min=0"`
 	RunAsGroup               *int64          `yaml:"runAsGroup,omitempty" validate:"omitempty,min=0"`
-	RunAsNonRoot             *bool           `yaml:"runAsNonRoot,omitempty"`
-	ReadOnlyRootFilesystem   *bool           `yaml:"readOnlyRootFilesystem,omitempty"`
-	AllowPrivilegeEscalation *bool           `yaml:"allowPrivilegeEscalation,omitempty"`
+	RunAsNonRoot             *bool           `yaml:"runAsNonRoot,omitempty" default:"true"`
+	ReadOnlyRootFilesystem   *bool           `yaml:"readOnlyRootFilesystem,omitempty" default:"true"`
+	AllowPrivilegeEscalation *bool           `yaml:"allowPrivilegeEscalation,omitempty" default:"false"`
 	Privileged               *bool           `yaml:"privileged,omitempty"`
-	Capabilities             *Capabilities   `yaml:"capabilities,omitempty"`
-	SeccompProfile           *SeccompProfile `yaml:"seccompProfile,omitempty"`
+	Capabilities             *Capabilities   `yaml:"capabilities,omitempty" default.drop:"[ALL]"`
+	SeccompProfile           *SeccompProfile `yaml:"seccompProfile,omitempty" default.type:"RuntimeDefault"`
 	SELinuxOptions           *SELinuxOptions `yaml:"seLinuxOptions,omitempty"`
 	WindowsOptions           *WindowsOptions `yaml:"windowsOptions,omitempty"`
 }
@@ -284,17 +284,26 @@ type ContainerPort struct {
 
 // Validate validates the entire Values configuration
 func (v *Values) Validate() error {
-	return helmcharts.ValidateStruct(v)
+	var errs helmcharts.Errors
+	errs.Struct(v)
+	errs.Nested("Controller", v.Controller.Validate())
+	return errs.Err()
 }
 
 // Validate validates the ControllerConfig
 func (c *ControllerConfig) Validate() error {
-	return helmcharts.ValidateStruct(c)
+	var errs helmcharts.Errors
+	errs.Struct(c)
+	errs.Nested("ManagerContainer", c.ManagerContainer.Validate())
+	return errs.Err()
 }
 
 // Validate validates the ManagerContainerConfig
 func (m *ManagerContainerConfig) Validate() error {
-	return helmcharts.ValidateStruct(m)
+	var errs helmcharts.Errors
+	errs.Struct(m)
+	errs.Nested("Resources", m.Resources.Validate())
+	return errs.Err()
 }
 
 // Validate validates the HTTPProbeConfig
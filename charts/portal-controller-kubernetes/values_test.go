@@ -1,34 +1,23 @@
 package portal_controller_kubernetes
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
-	"gopkg.in/yaml.v3"
+	helmcharts "github.com/tacokumo/helm-charts"
 )
 
 func TestLoadAndValidateValuesYAML(t *testing.T) {
 	// Get the path to the values.yaml file
 	valuesPath := filepath.Join("values.yaml")
 
-	// Read the values.yaml file
-	data, err := os.ReadFile(valuesPath)
+	// Strictly decode and validate the values.yaml file
+	values, report, err := helmcharts.LoadValues[Values](valuesPath)
 	if err != nil {
-		t.Fatalf("Failed to read values.yaml: %v", err)
+		t.Fatalf("Failed to load values.yaml: %v", err)
 	}
-
-	// Parse the YAML
-	var values Values
-	err = yaml.Unmarshal(data, &values)
-	if err != nil {
-		t.Fatalf("Failed to unmarshal values.yaml: %v", err)
-	}
-
-	// Validate the configuration
-	err = values.Validate()
-	if err != nil {
-		t.Errorf("values.yaml validation failed: %v", err)
+	if !report.Valid() {
+		t.Errorf("values.yaml validation failed:\n%s", report)
 	}
 
 	// Additional specific checks
@@ -54,14 +43,14 @@ func TestCRDsConfigValidation(t *testing.T) {
 		{
 			name: "CRDs enabled",
 			config: CRDsConfig{
-				Install: true,
+				Install: boolPtr(true),
 			},
 			wantErr: false,
 		},
 		{
 			name: "CRDs disabled",
 			config: CRDsConfig{
-				Install: false,
+				Install: boolPtr(false),
 			},
 			wantErr: false,
 		},
@@ -717,3 +706,8 @@ func TestMetricsConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+// Helper function to create bool pointers for test cases
+func boolPtr(b bool) *bool {
+	return &b
+}
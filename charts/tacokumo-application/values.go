@@ -1,8 +1,6 @@
 package tacokumo_application
 
 import (
-	"fmt"
-
 	helmcharts "github.com/tacokumo/helm-charts"
 )
 
@@ -13,10 +11,10 @@ type Values struct {
 
 // MainConfig represents the main application configuration
 type MainConfig struct {
-	ApplicationName  string            `yaml:"applicationName" validate:"required"`
-	Image            string            `yaml:"image" validate:"required"`
-	ImagePullSecrets []ImagePullSecret `yaml:"imagePullSecrets,omitempty" validate:"dive"`
-	ImagePullPolicy  string            `yaml:"imagePullPolicy" validate:"omitempty,oneof=Always IfNotPresent Never"`
+	ApplicationName  string            `yaml:"applicationName" validate:"required" default:"nginx-app"`
+	Image            string            `yaml:"image" validate:"required,image_reference" image:"true" default:"nginx:1.27"`
+	ImagePullSecrets []ImagePullSecret `yaml:"imagePullSecrets,omitempty" validate:"dive" default:"[]"`
+	ImagePullPolicy  string            `yaml:"imagePullPolicy" validate:"omitempty,oneof=Always IfNotPresent Never" default:"IfNotPresent"`
 
 	// HPA configuration
 	HPA HPAConfig `yaml:"hpa" validate:"required"`
@@ -34,11 +32,11 @@ type MainConfig struct {
 	Resources ResourceConfig `yaml:"resources,omitempty"`
 
 	// Annotations for various Kubernetes resources
-	Annotations    map[string]string `yaml:"annotations,omitempty"`
-	PodAnnotations map[string]string `yaml:"podAnnotations,omitempty"`
+	Annotations    map[string]string `yaml:"annotations,omitempty" default:"{tacokumo.io/managed-by: portal-controller}"`
+	PodAnnotations map[string]string `yaml:"podAnnotations,omitempty" default:"{tacokumo.io/managed-by: portal-controller}"`
 
 	// Environment configuration
-	EnvFrom []EnvFromSource `yaml:"envFrom,omitempty" validate:"dive"`
+	EnvFrom []EnvFromSource `yaml:"envFrom,omitempty" validate:"dive" default:"[]"`
 
 	// Health check probes
 	LivenessProbe  ProbeConfig `yaml:"livenessProbe"`
@@ -49,8 +47,8 @@ type MainConfig struct {
 // ServiceConfig represents Kubernetes Service configuration
 type ServiceConfig struct {
 	Enabled bool                `yaml:"enabled"`
-	Type    string              `yaml:"type,omitempty" validate:"omitempty,oneof=ClusterIP NodePort LoadBalancer"`
-	Ports   []ServicePortConfig `yaml:"ports" validate:"required_if=Enabled true,dive"`
+	Type    string              `yaml:"type,omitempty" validate:"omitempty,oneof=ClusterIP NodePort LoadBalancer" default:"ClusterIP"`
+	Ports   []ServicePortConfig `yaml:"ports" validate:"required_if=Enabled true,dive" default:"[{name: http, port: 80}]"`
 }
 
 // ServicePortConfig represents a single port configuration for a Service
@@ -58,15 +56,15 @@ type ServicePortConfig struct {
 	Name       string `yaml:"name,omitempty"`
 	Port       int    `yaml:"port" validate:"required,min=1,max=65535"`
 	TargetPort int    `yaml:"targetPort,omitempty" validate:"omitempty,min=1,max=65535"`
-	Protocol   string `yaml:"protocol,omitempty" validate:"omitempty,oneof=TCP UDP SCTP"`
+	Protocol   string `yaml:"protocol,omitempty" validate:"omitempty,oneof=TCP UDP SCTP" default:"TCP"`
 	NodePort   int    `yaml:"nodePort,omitempty" validate:"omitempty,min=30000,max=32767"`
 }
 
 // HPAConfig represents HorizontalPodAutoscaler configuration
 type HPAConfig struct {
-	MinReplicas                       int `yaml:"minReplicas" validate:"min=1"`
-	MaxReplicas                       int `yaml:"maxReplicas" validate:"min=1,gtefield=MinReplicas"`
-	TargetMemoryUtilizationPercentage int `yaml:"targetMemoryUtilizationPercentage" validate:"min=1,max=100"`
+	MinReplicas                       int `yaml:"minReplicas" validate:"min=1" default:"1"`
+	MaxReplicas                       int `yaml:"maxReplicas" validate:"min=1,gtefield=MinReplicas" default:"1"`
+	TargetMemoryUtilizationPercentage int `yaml:"targetMemoryUtilizationPercentage" validate:"min=1,max=100" default:"80"`
 }
 
 // ResourceConfig represents container resource limits and requests
@@ -77,8 +75,8 @@ type ResourceConfig struct {
 
 // ResourceSpec represents CPU and memory resource specifications
 type ResourceSpec struct {
-	CPU    string `yaml:"cpu,omitempty"`
-	Memory string `yaml:"memory,omitempty"`
+	CPU    string `yaml:"cpu,omitempty" validate:"omitempty,resource_quantity"`
+	Memory string `yaml:"memory,omitempty" validate:"omitempty,resource_quantity"`
 }
 
 // ImagePullSecret represents image pull secret configuration
@@ -154,21 +152,21 @@ type HTTPHeader struct {
 type IngressConfig struct {
 	Enabled     bool              `yaml:"enabled"`
 	ClassName   string            `yaml:"className,omitempty" validate:"required_if=Enabled true"`
-	Annotations map[string]string `yaml:"annotations,omitempty"`
-	Hosts       []IngressHost     `yaml:"hosts,omitempty" validate:"required_if=Enabled true,dive"`
-	TLS         []IngressTLS      `yaml:"tls,omitempty" validate:"dive"`
+	Annotations map[string]string `yaml:"annotations,omitempty" default:"{}"`
+	Hosts       []IngressHost     `yaml:"hosts,omitempty" validate:"required_if=Enabled true,dive" default:"[{host: app.example.com}]"`
+	TLS         []IngressTLS      `yaml:"tls,omitempty" validate:"dive" default:"[]"`
 }
 
 // IngressHost represents ingress host configuration for tacokumo-application
 type IngressHost struct {
 	Host  string        `yaml:"host" validate:"required,fqdn"`
-	Paths []IngressPath `yaml:"paths" validate:"required,dive"`
+	Paths []IngressPath `yaml:"paths" validate:"required,dive" default:"[{}]"`
 }
 
 // IngressPath represents ingress path configuration for tacokumo-application
 type IngressPath struct {
-	Path     string `yaml:"path" validate:"required"`
-	PathType string `yaml:"pathType" validate:"required,oneof=Exact Prefix ImplementationSpecific"`
+	Path     string `yaml:"path" validate:"required" default:"/"`
+	PathType string `yaml:"pathType" validate:"required,oneof=Exact Prefix ImplementationSpecific" default:"Prefix"`
 }
 
 // IngressTLS represents ingress TLS configuration for tacokumo-application
@@ -185,9 +183,9 @@ type RouteConfig struct {
 // HTTPRouteConfig represents Gateway API HTTPRoute configuration for tacokumo-application
 type HTTPRouteConfig struct {
 	Enabled     bool                  `yaml:"enabled"`
-	ParentRefs  []HTTPRouteParentRef  `yaml:"parentRefs,omitempty" validate:"required_if=Enabled true,dive"`
-	Hostnames   []string              `yaml:"hostnames,omitempty" validate:"required_if=Enabled true,dive,fqdn"`
-	Rules       []HTTPRouteRule       `yaml:"rules,omitempty" validate:"required_if=Enabled true,dive"`
+	ParentRefs  []HTTPRouteParentRef  `yaml:"parentRefs,omitempty" validate:"required_if=Enabled true,dive" default:"[{name: default-gateway, namespace: gateway-system}]"`
+	Hostnames   []string              `yaml:"hostnames,omitempty" validate:"required_if=Enabled true,dive,fqdn" default:"[app.example.com]"`
+	Rules       []HTTPRouteRule       `yaml:"rules,omitempty" validate:"required_if=Enabled true,dive" default:"[{}]"`
 }
 
 // HTTPRouteParentRef represents HTTPRoute parent reference for tacokumo-application
@@ -198,43 +196,67 @@ type HTTPRouteParentRef struct {
 
 // HTTPRouteRule represents HTTPRoute rule for tacokumo-application
 type HTTPRouteRule struct {
-	Matches []HTTPRouteMatch `yaml:"matches,omitempty" validate:"dive"`
+	Matches []HTTPRouteMatch `yaml:"matches,omitempty" validate:"dive" default:"[{}]"`
 }
 
 // HTTPRouteMatch represents HTTPRoute match for tacokumo-application
 type HTTPRouteMatch struct {
-	Path *HTTPRoutePath `yaml:"path,omitempty"`
+	Path *HTTPRoutePath `yaml:"path,omitempty" default:"{}"`
 }
 
 // HTTPRoutePath represents HTTPRoute path match for tacokumo-application
 type HTTPRoutePath struct {
-	Type  string `yaml:"type" validate:"required,oneof=PathPrefix Exact RegularExpression"`
-	Value string `yaml:"value" validate:"required"`
+	Type  string `yaml:"type" validate:"required,oneof=PathPrefix Exact RegularExpression" default:"PathPrefix"`
+	Value string `yaml:"value" validate:"required" default:"/"`
 }
 
 // Validate validates the entire Values configuration
 func (v *Values) Validate() error {
-	return helmcharts.ValidateStruct(v)
+	var errs helmcharts.Errors
+	errs.Struct(v)
+	errs.Nested("Main", v.Main.Validate())
+	return errs.Err()
 }
 
 // Validate validates the MainConfig
 func (m *MainConfig) Validate() error {
-	if err := helmcharts.ValidateStruct(m); err != nil {
-		return err
-	}
+	var errs helmcharts.Errors
+	errs.Struct(m)
+	// Validate ResourceConfig
+	errs.Nested("Resources", m.Resources.Validate())
 	// Validate nested ServiceConfig with custom validation
-	if err := m.Service.Validate(); err != nil {
-		return err
-	}
+	errs.Nested("Service", m.Service.Validate())
 	// Validate IngressConfig
-	if err := m.Ingress.Validate(); err != nil {
-		return err
-	}
+	errs.Nested("Ingress", m.Ingress.Validate())
 	// Validate RouteConfig
-	if err := m.Route.Validate(); err != nil {
-		return err
+	errs.Nested("Route", m.Route.Validate())
+	return errs.Err()
+}
+
+// ImageReference parses the image of the main container
+func (m *MainConfig) ImageReference() (helmcharts.ImageReference, error) {
+	return helmcharts.ParseImageReference(m.Image)
+}
+
+// ValidateImagePolicy validates the images of the chart against policy, e.g.
+// helmcharts.ImagePolicyForStage("prod"). Malformed references are left to Validate.
+func (v *Values) ValidateImagePolicy(policy helmcharts.ImagePolicy) error {
+	var errs helmcharts.Errors
+	errs.Nested("Main", v.Main.ValidateImagePolicy(policy))
+	return errs.Err()
+}
+
+// ValidateImagePolicy validates the image of the main container against policy
+func (m *MainConfig) ValidateImagePolicy(policy helmcharts.ImagePolicy) error {
+	ref, err := m.ImageReference()
+	if err != nil {
+		return nil
+	}
+	var errs helmcharts.Errors
+	if err := policy.Check(ref); err != nil {
+		errs.Add("Image", "image_policy", err.Error())
 	}
-	return nil
+	return errs.Err()
 }
 
 // Validate validates the ProbeConfig
@@ -244,14 +266,13 @@ func (p *ProbeConfig) Validate() error {
 
 // Validate validates the ServiceConfig
 func (s *ServiceConfig) Validate() error {
-	if err := helmcharts.ValidateStruct(s); err != nil {
-		return err
-	}
+	var errs helmcharts.Errors
+	errs.Struct(s)
 	// Additional validation: when enabled, ports must not be empty
 	if s.Enabled && len(s.Ports) == 0 {
-		return fmt.Errorf("Service.Ports: ports are required when service is enabled")
+		errs.Add("Ports", "required_if", "ports are required when service is enabled")
 	}
-	return nil
+	return errs.Err()
 }
 
 // Validate validates the IngressConfig
@@ -268,3 +289,35 @@ func (r *RouteConfig) Validate() error {
 func (h *HTTPRouteConfig) Validate() error {
 	return helmcharts.ValidateStruct(h)
 }
+
+// RoutedHostnames returns the hosts and TLS hosts of the IngressConfig when it is enabled
+func (i *IngressConfig) RoutedHostnames() []string {
+	if !i.Enabled {
+		return nil
+	}
+	var hosts []string
+	for _, h := range i.Hosts {
+		hosts = append(hosts, h.Host)
+	}
+	for _, t := range i.TLS {
+		hosts = append(hosts, t.Hosts...)
+	}
+	return hosts
+}
+
+// RoutedHostnames returns the hostnames of the HTTPRouteConfig when it is enabled
+func (h *HTTPRouteConfig) RoutedHostnames() []string {
+	if !h.Enabled {
+		return nil
+	}
+	return h.Hostnames
+}
+
+// Validate validates the ResourceConfig, including that requests do not exceed limits
+func (r *ResourceConfig) Validate() error {
+	var errs helmcharts.Errors
+	errs.Struct(r)
+	errs.RequestWithinLimit("Requests.CPU", r.Requests.CPU, r.Limits.CPU)
+	errs.RequestWithinLimit("Requests.Memory", r.Requests.Memory, r.Limits.Memory)
+	return errs.Err()
+}
@@ -1,34 +1,50 @@
 package tacokumo_application
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
-	"gopkg.in/yaml.v3"
+	helmcharts "github.com/tacokumo/helm-charts"
 )
 
 func TestLoadAndValidateValuesYAML(t *testing.T) {
 	// Get the path to the values.yaml file
 	valuesPath := filepath.Join("values.yaml")
 
-	// Read the values.yaml file
-	data, err := os.ReadFile(valuesPath)
+	// Strictly decode and validate the values.yaml file
+	_, report, err := helmcharts.LoadValues[Values](valuesPath)
 	if err != nil {
-		t.Fatalf("Failed to read values.yaml: %v", err)
+		t.Fatalf("Failed to load values.yaml: %v", err)
+	}
+	if !report.Valid() {
+		t.Errorf("values.yaml validation failed:\n%s", report)
 	}
+}
+
+func TestValuesValidationReport(t *testing.T) {
+	data := []byte(`main:
+  applicationName: test-app
+  image: nginx:latest
+  hpa:
+    minReplicas: 3
+    maxReplicas: 1
+    targetMemoryUtilizationPercentage: 80
+`)
 
-	// Parse the YAML
 	var values Values
-	err = yaml.Unmarshal(data, &values)
+	report, err := helmcharts.ValidateYAML("values.yaml", data, &values)
 	if err != nil {
-		t.Fatalf("Failed to unmarshal values.yaml: %v", err)
+		t.Fatalf("Failed to unmarshal values: %v", err)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
 	}
 
-	// Validate the configuration
-	err = values.Validate()
-	if err != nil {
-		t.Errorf("values.yaml validation failed: %v", err)
+	want := "values.yaml:6:5: main.hpa.maxReplicas: must be greater than or equal to minReplicas (got 1)"
+	if got := report.Issues[0].String(); got != want {
+		t.Errorf("issue = %q, want %q", got, want)
 	}
 }
 
@@ -78,6 +94,20 @@ func TestMainConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "malformed image",
+			config: MainConfig{
+				ApplicationName: "test-app",
+				Image:           "nginx::latest",
+				ImagePullPolicy: "IfNotPresent",
+				HPA: HPAConfig{
+					MinReplicas:                       1,
+					MaxReplicas:                       1,
+					TargetMemoryUtilizationPercentage: 80,
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "zero min replicas",
 			config: MainConfig{
@@ -146,6 +176,129 @@ func TestMainConfigValidation(t *testing.T) {
 	}
 }
 
+func TestImageValidationReport(t *testing.T) {
+	data := []byte(`main:
+  applicationName: test-app
+  image: UPPER/case:1.0
+  hpa:
+    minReplicas: 1
+    maxReplicas: 1
+    targetMemoryUtilizationPercentage: 80
+`)
+
+	var values Values
+	report, err := helmcharts.ValidateYAML("values.yaml", data, &values)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal values: %v", err)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
+	}
+
+	want := `values.yaml:3:3: main.image: must be a container image reference: repository "UPPER/case" must be lower case (got "UPPER/case:1.0")`
+	if got := report.Issues[0].String(); got != want {
+		t.Errorf("issue = %q, want %q", got, want)
+	}
+}
+
+func TestValuesValidateImagePolicy(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name  string
+		image string
+		stage string
+		want  string
+	}{
+		{name: "latest in dev", image: "nginx:latest", stage: "dev", want: "Main.Image: image nginx:latest must not use the latest tag; pin a version"},
+		{name: "untagged in dev", image: "ghcr.io/tacokumo/app", stage: "dev", want: "Main.Image: image ghcr.io/tacokumo/app has no tag, so latest is pulled; pin a version"},
+		{name: "pinned tag in dev", image: "ghcr.io/tacokumo/app:1.4.2", stage: "dev"},
+		{name: "pinned tag in prod", image: "ghcr.io/tacokumo/app:1.4.2", stage: "prod", want: "Main.Image: image ghcr.io/tacokumo/app:1.4.2 must be pinned to a digest such as @sha256:…"},
+		{name: "digest in prod", image: "ghcr.io/tacokumo/app:1.4.2@" + digest, stage: "prod"},
+		{name: "malformed image is left to Validate", image: "nginx::latest", stage: "prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := Values{Main: MainConfig{Image: tt.image}}
+			err := values.ValidateImagePolicy(helmcharts.ImagePolicyForStage(tt.stage))
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("ValidateImagePolicy() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ValidateImagePolicy() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImagePolicyReport(t *testing.T) {
+	data, err := os.ReadFile("values.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read values.yaml: %v", err)
+	}
+	doc, err := helmcharts.ParseDocument("values.yaml", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values Values
+	if err := doc.Decode(&values); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default image is pinned to a tag, which only production stages reject
+	if err := values.ValidateImagePolicy(helmcharts.ImagePolicyForStage("dev")); err != nil {
+		t.Errorf("ValidateImagePolicy(dev) error = %v, want the default image to pass", err)
+	}
+	report := helmcharts.NewValidationReport(&values, values.ValidateImagePolicy(helmcharts.ImagePolicyForStage("prod")), doc)
+	want := "values.yaml:3:3: main.image: image nginx:1.27 must be pinned to a digest such as @sha256:…"
+	if len(report.Issues) != 1 || report.Issues[0].String() != want {
+		t.Errorf("report =\n%s\nwant %s", report, want)
+	}
+}
+
+func TestMainConfigValidationCollectsAllErrors(t *testing.T) {
+	config := MainConfig{
+		Image: "nginx:latest",
+		HPA: HPAConfig{
+			MinReplicas:                       0,
+			MaxReplicas:                       1,
+			TargetMemoryUtilizationPercentage: 80,
+		},
+		Service: ServiceConfig{
+			Enabled: true,
+		},
+		Ingress: IngressConfig{
+			Enabled: true,
+		},
+	}
+
+	err := config.Validate()
+	var errs helmcharts.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("MainConfig validation error = %v, want helmcharts.Errors", err)
+	}
+
+	want := []string{
+		"ApplicationName",
+		"HPA.MinReplicas",
+		"Service.Ports",
+		"Ingress.ClassName",
+		"Ingress.Hosts",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(want), errs)
+	}
+	for i, field := range want {
+		var fe *helmcharts.FieldError
+		if !errors.As(errs[i], &fe) || fe.Field != field {
+			t.Errorf("error %d = %v, want field %s", i, errs[i], field)
+		}
+	}
+}
+
 func TestProbeConfigValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -431,6 +584,53 @@ func TestResourceConfigValidation(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "requests equal to limits in other units",
+			resources: ResourceConfig{
+				Limits: ResourceSpec{
+					CPU:    "1",
+					Memory: "1Gi",
+				},
+				Requests: ResourceSpec{
+					CPU:    "1000m",
+					Memory: "1073741824",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "cpu request exceeds limit",
+			resources: ResourceConfig{
+				Limits: ResourceSpec{
+					CPU: "500m",
+				},
+				Requests: ResourceSpec{
+					CPU: "1",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "memory request exceeds limit",
+			resources: ResourceConfig{
+				Limits: ResourceSpec{
+					Memory: "1G",
+				},
+				Requests: ResourceSpec{
+					Memory: "1Gi",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid quantity",
+			resources: ResourceConfig{
+				Requests: ResourceSpec{
+					Memory: "128mb",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1219,3 +1419,91 @@ func TestHTTPRouteConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeValuesWithOverlay(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("values.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read values.yaml: %v", err)
+	}
+	base, err := helmcharts.ParseDocument("values.yaml", data)
+	if err != nil {
+		t.Fatalf("Failed to parse values.yaml: %v", err)
+	}
+	overlay, err := helmcharts.ParseDocument("prod.yaml", []byte(`main:
+  service:
+    enabled: true
+    ports:
+      - name: https
+        port: 443
+  ingress:
+    enabled: true
+    hosts:
+      - host: app.tacokumo.dev
+        paths:
+          - path: /
+            pathType: Prefix
+  podAnnotations: null
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse overlay: %v", err)
+	}
+
+	values, report, err := helmcharts.MergeValues[Values](base, overlay)
+	if err != nil {
+		t.Fatalf("MergeValues() error = %v", err)
+	}
+
+	// Lists are replaced, maps are merged and null removes the default
+	if len(values.Main.Service.Ports) != 1 || values.Main.Service.Ports[0].Port != 443 {
+		t.Errorf("Service.Ports = %+v, want only port 443", values.Main.Service.Ports)
+	}
+	if values.Main.Service.Type != "ClusterIP" {
+		t.Errorf("Service.Type = %q, want ClusterIP from values.yaml", values.Main.Service.Type)
+	}
+	if values.Main.PodAnnotations != nil {
+		t.Errorf("PodAnnotations = %v, want nil", values.Main.PodAnnotations)
+	}
+
+	// className is still empty in values.yaml, so enabling ingress fails there
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
+	}
+	if want := "values.yaml:29:5: main.ingress.className: is required when enabled is true"; report.Issues[0].String() != want {
+		t.Errorf("issue = %q, want %q", report.Issues[0].String(), want)
+	}
+}
+
+func TestMergeValuesWithSetFlags(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("values.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read values.yaml: %v", err)
+	}
+	base, err := helmcharts.ParseDocument("values.yaml", data)
+	if err != nil {
+		t.Fatalf("Failed to parse values.yaml: %v", err)
+	}
+	set, err := helmcharts.ParseSet(helmcharts.SetFlag, "main.image=nginx:1.27,main.service.enabled=true,main.service.ports[0].port=8443,main.service.ports[1].name=metrics,main.service.ports[1].port=70000")
+	if err != nil {
+		t.Fatalf("ParseSet() error = %v", err)
+	}
+
+	values, report, err := helmcharts.MergeValues[Values](base, set)
+	if err != nil {
+		t.Fatalf("MergeValues() error = %v", err)
+	}
+
+	if values.Main.Image != "nginx:1.27" || !values.Main.Service.Enabled {
+		t.Errorf("Main = %+v, want image nginx:1.27 with the service enabled", values.Main)
+	}
+	ports := values.Main.Service.Ports
+	if len(ports) != 2 || ports[0].Name != "http" || ports[0].Port != 8443 || ports[0].Protocol != "TCP" {
+		t.Errorf("Service.Ports = %+v, want http on 8443 followed by metrics", ports)
+	}
+
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
+	}
+	if got := report.Issues[0]; got.Path != "main.service.ports[1].port" || got.File != "--set" {
+		t.Errorf("issue = %s, want main.service.ports[1].port in --set", got)
+	}
+}
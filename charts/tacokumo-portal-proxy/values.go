@@ -11,11 +11,11 @@ type Values struct {
 
 // PortalProxyConfig represents the portal proxy service configuration
 type PortalProxyConfig struct {
-	ReplicaCount int    `yaml:"replicaCount" validate:"min=1"`
-	BaseDomain   string `yaml:"baseDomain" validate:"required,fqdn"`
+	ReplicaCount int    `yaml:"replicaCount" validate:"min=1" default:"1"`
+	BaseDomain   string `yaml:"baseDomain" validate:"required,fqdn" default:"tacokumo.dev"`
 
 	// Container image configuration
-	Image helmcharts.Image `yaml:"image" validate:"required"`
+	Image helmcharts.Image `yaml:"image" validate:"required" image:"true" default.repository:"caddy" default.tag:"2.11" default.pullPolicy:"IfNotPresent"`
 
 	// Service configuration
 	Service ProxyServiceConfig `yaml:"service" validate:"required"`
@@ -27,14 +27,14 @@ type PortalProxyConfig struct {
 	Route RouteConfig `yaml:"route"`
 
 	// Resource limits and requests
-	Resources helmcharts.Resources `yaml:"resources"`
+	Resources helmcharts.Resources `yaml:"resources" default.limits.cpu:"100m" default.limits.memory:"128Mi" default.requests.cpu:"10m" default.requests.memory:"32Mi"`
 
 	// Health check probes
-	LivenessProbe  helmcharts.HTTPProbe `yaml:"livenessProbe"`
-	ReadinessProbe helmcharts.HTTPProbe `yaml:"readinessProbe"`
+	LivenessProbe  ProbeConfig `yaml:"livenessProbe" default.httpGet.path:"/" default.httpGet.port:"80" default.initialDelaySeconds:"10" default.periodSeconds:"30"`
+	ReadinessProbe ProbeConfig `yaml:"readinessProbe" default.httpGet.path:"/" default.httpGet.port:"80" default.initialDelaySeconds:"5" default.periodSeconds:"10"`
 
 	// Security context
-	SecurityContext helmcharts.SecurityContext `yaml:"securityContext"`
+	SecurityContext helmcharts.SecurityContext `yaml:"securityContext" default.runAsNonRoot:"true" default.runAsUser:"2019" default.readOnlyRootFilesystem:"true" default.allowPrivilegeEscalation:"false" default.capabilities.drop:"[ALL]"`
 
 	// Additional configurations
 	Annotations      map[string]string      `yaml:"annotations,omitempty"`
@@ -58,9 +58,9 @@ type PortalProxyConfig struct {
 
 // ProxyServiceConfig represents proxy service specific configuration
 type ProxyServiceConfig struct {
-	Type        string            `yaml:"type" validate:"oneof=ClusterIP NodePort LoadBalancer ExternalName"`
-	HTTPPort    int               `yaml:"httpPort" validate:"min=1,max=65535"`
-	MetricsPort int               `yaml:"metricsPort" validate:"min=1,max=65535"`
+	Type        string            `yaml:"type" validate:"oneof=ClusterIP NodePort LoadBalancer ExternalName" default:"ClusterIP"`
+	HTTPPort    int               `yaml:"httpPort" validate:"min=1,max=65535" default:"80"`
+	MetricsPort int               `yaml:"metricsPort" validate:"min=1,max=65535" default:"2019"`
 	Annotations map[string]string `yaml:"annotations,omitempty"`
 
 	// Additional service ports
@@ -83,6 +83,51 @@ type ServicePort struct {
 	NodePort   int    `yaml:"nodePort,omitempty" validate:"omitempty,min=30000,max=32767"`
 }
 
+// ProbeConfig represents health check probe configuration
+type ProbeConfig struct {
+	// HTTP probe configuration
+	HTTPGet *HTTPGetAction `yaml:"httpGet,omitempty"`
+
+	// TCP probe configuration
+	TCPSocket *TCPSocketAction `yaml:"tcpSocket,omitempty"`
+
+	// Exec probe configuration
+	Exec *ExecAction `yaml:"exec,omitempty"`
+
+	// Common probe settings
+	InitialDelaySeconds *int `yaml:"initialDelaySeconds,omitempty" validate:"omitempty,min=0"`
+	PeriodSeconds       *int `yaml:"periodSeconds,omitempty" validate:"omitempty,min=1"`
+	TimeoutSeconds      *int `yaml:"timeoutSeconds,omitempty" validate:"omitempty,min=1"`
+	SuccessThreshold    *int `yaml:"successThreshold,omitempty" validate:"omitempty,min=1"`
+	FailureThreshold    *int `yaml:"failureThreshold,omitempty" validate:"omitempty,min=1"`
+}
+
+// HTTPGetAction represents HTTP GET action for probes
+type HTTPGetAction struct {
+	Path        string       `yaml:"path" validate:"required"`
+	Port        int          `yaml:"port" validate:"required,min=1,max=65535"`
+	Host        string       `yaml:"host,omitempty" validate:"omitempty,fqdn|ip"`
+	Scheme      string       `yaml:"scheme,omitempty" validate:"omitempty,oneof=HTTP HTTPS"`
+	HTTPHeaders []HTTPHeader `yaml:"httpHeaders,omitempty" validate:"dive"`
+}
+
+// TCPSocketAction represents TCP socket action for probes
+type TCPSocketAction struct {
+	Port int    `yaml:"port" validate:"required,min=1,max=65535"`
+	Host string `yaml:"host,omitempty" validate:"omitempty,fqdn|ip"`
+}
+
+// ExecAction represents exec action for probes
+type ExecAction struct {
+	Command []string `yaml:"command" validate:"required,min=1"`
+}
+
+// HTTPHeader represents HTTP header for probes
+type HTTPHeader struct {
+	Name  string `yaml:"name" validate:"required"`
+	Value string `yaml:"value" validate:"required"`
+}
+
 // ImagePullSecret represents image pull secret configuration
 type ImagePullSecret struct {
 	Name string `yaml:"name" validate:"required"`
@@ -262,21 +307,21 @@ type DownwardAPIVolumeFile struct {
 type IngressConfig struct {
 	Enabled     bool              `yaml:"enabled"`
 	ClassName   string            `yaml:"className,omitempty" validate:"required_if=Enabled true"`
-	Annotations map[string]string `yaml:"annotations,omitempty"`
-	Hosts       []IngressHost     `yaml:"hosts,omitempty" validate:"required_if=Enabled true,dive"`
-	TLS         []IngressTLS      `yaml:"tls,omitempty" validate:"dive"`
+	Annotations map[string]string `yaml:"annotations,omitempty" default:"{}"`
+	Hosts       []IngressHost     `yaml:"hosts,omitempty" validate:"required_if=Enabled true,dive" default:"[{host: 'proxy.{{ .Values.portalProxy.baseDomain }}'}]"`
+	TLS         []IngressTLS      `yaml:"tls,omitempty" validate:"dive" default:"[]"`
 }
 
 // IngressHost represents ingress host configuration for tacokumo-portal-proxy
 type IngressHost struct {
-	Host  string        `yaml:"host" validate:"required,fqdn"`
-	Paths []IngressPath `yaml:"paths" validate:"required,dive"`
+	Host  string        `yaml:"host" validate:"required,fqdn" tpl:"true"`
+	Paths []IngressPath `yaml:"paths" validate:"required,dive" default:"[{}]"`
 }
 
 // IngressPath represents ingress path configuration for tacokumo-portal-proxy
 type IngressPath struct {
-	Path     string `yaml:"path" validate:"required"`
-	PathType string `yaml:"pathType" validate:"required,oneof=Exact Prefix ImplementationSpecific"`
+	Path     string `yaml:"path" validate:"required" default:"/"`
+	PathType string `yaml:"pathType" validate:"required,oneof=Exact Prefix ImplementationSpecific" default:"Prefix"`
 }
 
 // IngressTLS represents ingress TLS configuration for tacokumo-portal-proxy
@@ -293,9 +338,9 @@ type RouteConfig struct {
 // HTTPRouteConfig represents Gateway API HTTPRoute configuration for tacokumo-portal-proxy
 type HTTPRouteConfig struct {
 	Enabled     bool                  `yaml:"enabled"`
-	ParentRefs  []HTTPRouteParentRef  `yaml:"parentRefs,omitempty" validate:"required_if=Enabled true,dive"`
-	Hostnames   []string              `yaml:"hostnames,omitempty" validate:"required_if=Enabled true,dive,fqdn"`
-	Rules       []HTTPRouteRule       `yaml:"rules,omitempty" validate:"required_if=Enabled true,dive"`
+	ParentRefs  []HTTPRouteParentRef  `yaml:"parentRefs,omitempty" validate:"required_if=Enabled true,dive" default:"[{name: default-gateway, namespace: gateway-system}]"`
+	Hostnames   []string              `yaml:"hostnames,omitempty" validate:"required_if=Enabled true,dive,fqdn" tpl:"true" default:"['proxy.{{ .Values.portalProxy.baseDomain }}']"`
+	Rules       []HTTPRouteRule       `yaml:"rules,omitempty" validate:"required_if=Enabled true,dive" default:"[{}]"`
 }
 
 // HTTPRouteParentRef represents HTTPRoute parent reference for tacokumo-portal-proxy
@@ -306,39 +351,46 @@ type HTTPRouteParentRef struct {
 
 // HTTPRouteRule represents HTTPRoute rule for tacokumo-portal-proxy
 type HTTPRouteRule struct {
-	Matches []HTTPRouteMatch `yaml:"matches,omitempty" validate:"dive"`
+	Matches []HTTPRouteMatch `yaml:"matches,omitempty" validate:"dive" default:"[{}]"`
 }
 
 // HTTPRouteMatch represents HTTPRoute match for tacokumo-portal-proxy
 type HTTPRouteMatch struct {
-	Path *HTTPRoutePath `yaml:"path,omitempty"`
+	Path *HTTPRoutePath `yaml:"path,omitempty" default:"{}"`
 }
 
 // HTTPRoutePath represents HTTPRoute path match for tacokumo-portal-proxy
 type HTTPRoutePath struct {
-	Type  string `yaml:"type" validate:"required,oneof=PathPrefix Exact RegularExpression"`
-	Value string `yaml:"value" validate:"required"`
+	Type  string `yaml:"type" validate:"required,oneof=PathPrefix Exact RegularExpression" default:"PathPrefix"`
+	Value string `yaml:"value" validate:"required" default:"/"`
 }
 
 // Validate validates the entire Values configuration
 func (v *Values) Validate() error {
-	return helmcharts.ValidateStruct(v)
+	var errs helmcharts.Errors
+	errs.Struct(v)
+	errs.Nested("PortalProxy", v.PortalProxy.Validate())
+	return errs.Err()
 }
 
 // Validate validates the PortalProxyConfig
 func (p *PortalProxyConfig) Validate() error {
-	if err := helmcharts.ValidateStruct(p); err != nil {
-		return err
-	}
+	var errs helmcharts.Errors
+	errs.Struct(p)
+	// Validate Resources
+	errs.Nested("Resources", p.Resources.Validate())
+	// Validate ProxyServiceConfig
+	errs.Nested("Service", p.Service.Validate())
 	// Validate IngressConfig
-	if err := p.Ingress.Validate(); err != nil {
-		return err
-	}
+	errs.Nested("Ingress", p.Ingress.Validate())
 	// Validate RouteConfig
-	if err := p.Route.Validate(); err != nil {
-		return err
-	}
-	return nil
+	errs.Nested("Route", p.Route.Validate())
+	return errs.Err()
+}
+
+// Validate validates the ProbeConfig
+func (p *ProbeConfig) Validate() error {
+	return helmcharts.ValidateStruct(p)
 }
 
 // Validate validates the ProxyServiceConfig
@@ -360,3 +412,26 @@ func (r *RouteConfig) Validate() error {
 func (h *HTTPRouteConfig) Validate() error {
 	return helmcharts.ValidateStruct(h)
 }
+
+// RoutedHostnames returns the hosts and TLS hosts of the IngressConfig when it is enabled
+func (i *IngressConfig) RoutedHostnames() []string {
+	if !i.Enabled {
+		return nil
+	}
+	var hosts []string
+	for _, h := range i.Hosts {
+		hosts = append(hosts, h.Host)
+	}
+	for _, t := range i.TLS {
+		hosts = append(hosts, t.Hosts...)
+	}
+	return hosts
+}
+
+// RoutedHostnames returns the hostnames of the HTTPRouteConfig when it is enabled
+func (h *HTTPRouteConfig) RoutedHostnames() []string {
+	if !h.Enabled {
+		return nil
+	}
+	return h.Hostnames
+}
@@ -1,34 +1,30 @@
 package tacokumo_portal_proxy
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
-	"gopkg.in/yaml.v3"
+	helmcharts "github.com/tacokumo/helm-charts"
 )
 
 func TestLoadAndValidateValuesYAML(t *testing.T) {
 	// Get the path to the values.yaml file
 	valuesPath := filepath.Join("values.yaml")
 
-	// Read the values.yaml file
-	data, err := os.ReadFile(valuesPath)
+	// Strictly decode and validate the values.yaml file
+	values, report, err := helmcharts.LoadValues[Values](valuesPath)
 	if err != nil {
-		t.Fatalf("Failed to read values.yaml: %v", err)
+		t.Fatalf("Failed to load values.yaml: %v", err)
 	}
 
-	// Parse the YAML
-	var values Values
-	err = yaml.Unmarshal(data, &values)
-	if err != nil {
-		t.Fatalf("Failed to unmarshal values.yaml: %v", err)
+	// Template references such as "proxy.{{ .Values.portalProxy.baseDomain }}"
+	// are resolved before validation, so the whole file must validate
+	if !report.Valid() {
+		t.Errorf("values.yaml validation failed:\n%s", report)
+	}
+	if got := values.PortalProxy.Route.HTTP.Hostnames[0]; got != "proxy.tacokumo.dev" {
+		t.Errorf("route hostname = %q, want proxy.tacokumo.dev", got)
 	}
-
-	// Note: values.yaml contains template strings like "proxy.{{ .Values.portalProxy.baseDomain }}"
-	// which are not valid FQDNs but will be resolved by Helm during template rendering.
-	// In actual deployment, these would be replaced with real domain names.
-	// For Go validation testing purposes, we skip validation of template strings.
 
 	// Check if YAML can be parsed successfully (basic structure validation)
 	if values.PortalProxy.BaseDomain == "" {
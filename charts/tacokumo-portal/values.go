@@ -1,8 +1,6 @@
 package tacokumo_portal
 
 import (
-	"fmt"
-
 	helmcharts "github.com/tacokumo/helm-charts"
 )
 
@@ -14,13 +12,13 @@ type Values struct {
 // APIConfig represents the API service configuration
 type APIConfig struct {
 	// PortalName is the portal namespace name (REQUIRED, maps to PORTAL_NAME env var)
-	PortalName string `yaml:"portalName" validate:"required"`
+	PortalName string `yaml:"portalName" validate:"required" default:"default-portal"`
 
 	// LogLevel is the logging level (optional: debug, info, warn, error)
-	LogLevel string `yaml:"logLevel" validate:"omitempty,oneof=debug info warn error"`
+	LogLevel string `yaml:"logLevel" validate:"omitempty,oneof=debug info warn error" default:"info"`
 
 	// Container image configuration
-	Image helmcharts.Image `yaml:"image" validate:"required"`
+	Image helmcharts.Image `yaml:"image" validate:"required" image:"true" default.repository:"ghcr.io/tacokumo/portal-api" default.tag:"latest" default.pullPolicy:"IfNotPresent"`
 
 	// HPA configuration
 	HPA HPAConfig `yaml:"hpa"`
@@ -32,14 +30,14 @@ type APIConfig struct {
 	Resources ResourceConfig `yaml:"resources,omitempty"`
 
 	// Health check probes
-	LivenessProbe  ProbeConfig `yaml:"livenessProbe"`
-	ReadinessProbe ProbeConfig `yaml:"readinessProbe"`
+	LivenessProbe  ProbeConfig `yaml:"livenessProbe" default.httpGet.path:"/health/liveness" default.httpGet.port:"1323" default.initialDelaySeconds:"15" default.periodSeconds:"20"`
+	ReadinessProbe ProbeConfig `yaml:"readinessProbe" default.httpGet.path:"/health/readiness" default.httpGet.port:"1323" default.initialDelaySeconds:"5" default.periodSeconds:"10"`
 
 	// Pod-level security context
-	SecurityContext SecurityContext `yaml:"securityContext"`
+	SecurityContext SecurityContext `yaml:"securityContext" default.runAsNonRoot:"true" default.runAsUser:"65532"`
 
 	// Container-level security context
-	ContainerSecurityContext SecurityContext `yaml:"containerSecurityContext"`
+	ContainerSecurityContext SecurityContext `yaml:"containerSecurityContext" default.readOnlyRootFilesystem:"true" default.allowPrivilegeEscalation:"false" default.capabilities.drop:"[ALL]"`
 
 	// RBAC configuration
 	RBAC RBACConfig `yaml:"rbac"`
@@ -48,64 +46,64 @@ type APIConfig struct {
 	ServiceAccount ServiceAccountConfig `yaml:"serviceAccount"`
 
 	// TerminationGracePeriodSeconds for the pod
-	TerminationGracePeriodSeconds int64 `yaml:"terminationGracePeriodSeconds" validate:"omitempty,min=0"`
+	TerminationGracePeriodSeconds int64 `yaml:"terminationGracePeriodSeconds" validate:"omitempty,min=0" default:"30"`
 
 	// Annotations for the deployment
-	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" default:"{}"`
 
 	// Annotations for pods
-	PodAnnotations map[string]string `yaml:"podAnnotations,omitempty"`
+	PodAnnotations map[string]string `yaml:"podAnnotations,omitempty" default:"{}"`
 
 	// Labels for the deployment
-	Labels map[string]string `yaml:"labels,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty" default:"{}"`
 
 	// Labels for pods
-	PodLabels map[string]string `yaml:"podLabels,omitempty"`
+	PodLabels map[string]string `yaml:"podLabels,omitempty" default:"{}"`
 
 	// NodeSelector for pod scheduling
-	NodeSelector map[string]string `yaml:"nodeSelector,omitempty"`
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty" default:"{}"`
 
 	// Tolerations for pod scheduling
-	Tolerations helmcharts.Tolerations `yaml:"tolerations,omitempty"`
+	Tolerations helmcharts.Tolerations `yaml:"tolerations,omitempty" default:"[]"`
 
 	// Affinity for pod scheduling
-	Affinity *helmcharts.Affinity `yaml:"affinity,omitempty"`
+	Affinity *helmcharts.Affinity `yaml:"affinity,omitempty" default:"{}"`
 
 	// ImagePullSecrets for pulling container images
-	ImagePullSecrets []ImagePullSecret `yaml:"imagePullSecrets,omitempty" validate:"dive"`
+	ImagePullSecrets []ImagePullSecret `yaml:"imagePullSecrets,omitempty" validate:"dive" default:"[]"`
 
 	// Additional environment variables
-	Env []EnvVar `yaml:"env,omitempty" validate:"dive"`
+	Env []EnvVar `yaml:"env,omitempty" validate:"dive" default:"[]"`
 
 	// Environment variables from ConfigMaps or Secrets
-	EnvFrom []EnvFromSource `yaml:"envFrom,omitempty" validate:"dive"`
+	EnvFrom []EnvFromSource `yaml:"envFrom,omitempty" validate:"dive" default:"[]"`
 }
 
 // HPAConfig represents HorizontalPodAutoscaler configuration
 type HPAConfig struct {
-	Enabled                           bool `yaml:"enabled"`
-	MinReplicas                       int  `yaml:"minReplicas" validate:"required_if=Enabled true,omitempty,min=1"`
-	MaxReplicas                       int  `yaml:"maxReplicas" validate:"required_if=Enabled true,omitempty,min=1,gtefield=MinReplicas"`
-	TargetMemoryUtilizationPercentage int  `yaml:"targetMemoryUtilizationPercentage" validate:"required_if=Enabled true,omitempty,min=1,max=100"`
+	Enabled                           *bool `yaml:"enabled" default:"true"`
+	MinReplicas                       int   `yaml:"minReplicas" validate:"required_if=Enabled true,omitempty,min=1" default:"1"`
+	MaxReplicas                       int   `yaml:"maxReplicas" validate:"required_if=Enabled true,omitempty,min=1,gtefield=MinReplicas" default:"3"`
+	TargetMemoryUtilizationPercentage int   `yaml:"targetMemoryUtilizationPercentage" validate:"required_if=Enabled true,omitempty,min=1,max=100" default:"80"`
 }
 
 // ServiceConfig represents Kubernetes Service configuration
 type ServiceConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Type    string `yaml:"type,omitempty" validate:"omitempty,oneof=ClusterIP NodePort LoadBalancer"`
-	Port    int    `yaml:"port" validate:"required_if=Enabled true,omitempty,min=1,max=65535"`
+	Enabled *bool  `yaml:"enabled" default:"true"`
+	Type    string `yaml:"type,omitempty" validate:"omitempty,oneof=ClusterIP NodePort LoadBalancer" default:"ClusterIP"`
+	Port    int    `yaml:"port" validate:"required_if=Enabled true,omitempty,min=1,max=65535" default:"1323"`
 }
 
 // ResourceConfig represents container resource limits and requests
 type ResourceConfig struct {
-	Limits   ResourceSpec `yaml:"limits,omitempty"`
-	Requests ResourceSpec `yaml:"requests,omitempty"`
+	Limits   ResourceSpec `yaml:"limits,omitempty" default.cpu:"500m" default.memory:"256Mi"`
+	Requests ResourceSpec `yaml:"requests,omitempty" default.cpu:"100m" default.memory:"128Mi"`
 }
 
 // ResourceSpec represents CPU and memory resource specifications
 type ResourceSpec struct {
-	CPU    string `yaml:"cpu,omitempty"`
-	Memory string `yaml:"memory,omitempty"`
+	CPU    string `yaml:"cpu,omitempty" validate:"omitempty,resource_quantity"`
+	Memory string `yaml:"memory,omitempty" validate:"omitempty,resource_quantity"`
 }
 
 // ProbeConfig represents health check probe configuration
@@ -178,14 +176,14 @@ type SeccompProfile struct {
 
 // RBACConfig represents RBAC configuration
 type RBACConfig struct {
-	Create bool `yaml:"create"`
+	Create *bool `yaml:"create" default:"true"`
 }
 
 // ServiceAccountConfig represents ServiceAccount configuration
 type ServiceAccountConfig struct {
-	Create      bool              `yaml:"create"`
-	Name        string            `yaml:"name" validate:"required_if=Create true"`
-	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Create      *bool             `yaml:"create" default:"true"`
+	Name        string            `yaml:"name" validate:"required_if=Create true" default:"portal-api"`
+	Annotations map[string]string `yaml:"annotations,omitempty" default:"{}"`
 }
 
 // ImagePullSecret represents image pull secret configuration
@@ -256,58 +254,65 @@ type SecretEnvSource struct {
 
 // Validate validates the entire Values configuration
 func (v *Values) Validate() error {
-	return helmcharts.ValidateStruct(v)
+	var errs helmcharts.Errors
+	errs.Struct(v)
+	errs.Nested("API", v.API.Validate())
+	return errs.Err()
 }
 
 // Validate validates the APIConfig
 func (a *APIConfig) Validate() error {
-	if err := helmcharts.ValidateStruct(a); err != nil {
-		return err
-	}
+	var errs helmcharts.Errors
+	errs.Struct(a)
+	// Validate ResourceConfig
+	errs.Nested("Resources", a.Resources.Validate())
 	// Validate nested ServiceConfig with custom validation
-	if err := a.Service.Validate(); err != nil {
-		return err
-	}
+	errs.Nested("Service", a.Service.Validate())
 	// Validate nested HPAConfig with custom validation
-	if err := a.HPA.Validate(); err != nil {
-		return err
-	}
-	return nil
+	errs.Nested("HPA", a.HPA.Validate())
+	return errs.Err()
 }
 
 // Validate validates the HPAConfig
 func (h *HPAConfig) Validate() error {
-	if err := helmcharts.ValidateStruct(h); err != nil {
-		return err
-	}
+	var errs helmcharts.Errors
+	errs.Struct(h)
 	// Additional validation: when enabled, all fields must be properly set
-	if h.Enabled {
+	if h.Enabled != nil && *h.Enabled {
 		if h.MinReplicas < 1 {
-			return fmt.Errorf("HPA.MinReplicas: must be at least 1 when HPA is enabled")
+			errs.Add("MinReplicas", "min", "must be at least 1 when HPA is enabled")
 		}
 		if h.MaxReplicas < h.MinReplicas {
-			return fmt.Errorf("HPA.MaxReplicas: must be greater than or equal to MinReplicas")
+			errs.Add("MaxReplicas", "gtefield", "must be greater than or equal to minReplicas")
 		}
 		if h.TargetMemoryUtilizationPercentage < 1 || h.TargetMemoryUtilizationPercentage > 100 {
-			return fmt.Errorf("HPA.TargetMemoryUtilizationPercentage: must be between 1 and 100")
+			errs.Add("TargetMemoryUtilizationPercentage", "range", "must be between 1 and 100")
 		}
 	}
-	return nil
+	return errs.Err()
 }
 
 // Validate validates the ServiceConfig
 func (s *ServiceConfig) Validate() error {
-	if err := helmcharts.ValidateStruct(s); err != nil {
-		return err
-	}
+	var errs helmcharts.Errors
+	errs.Struct(s)
 	// Additional validation: when enabled, port must be valid
-	if s.Enabled && s.Port == 0 {
-		return fmt.Errorf("Service.Port: port is required when service is enabled")
+	if s.Enabled != nil && *s.Enabled && s.Port == 0 {
+		errs.Add("Port", "required_if", "port is required when service is enabled")
 	}
-	return nil
+	return errs.Err()
 }
 
 // Validate validates the ProbeConfig
 func (p *ProbeConfig) Validate() error {
 	return helmcharts.ValidateStruct(p)
 }
+
+// Validate validates the ResourceConfig, including that requests do not exceed limits
+func (r *ResourceConfig) Validate() error {
+	var errs helmcharts.Errors
+	errs.Struct(r)
+	errs.RequestWithinLimit("Requests.CPU", r.Requests.CPU, r.Limits.CPU)
+	errs.RequestWithinLimit("Requests.Memory", r.Requests.Memory, r.Limits.Memory)
+	return errs.Err()
+}
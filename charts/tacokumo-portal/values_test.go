@@ -1,35 +1,23 @@
 package tacokumo_portal
 
 import (
-	"os"
 	"path/filepath"
 	"testing"
 
 	helmcharts "github.com/tacokumo/helm-charts"
-	"gopkg.in/yaml.v3"
 )
 
 func TestLoadAndValidateValuesYAML(t *testing.T) {
 	// Get the path to the values.yaml file
 	valuesPath := filepath.Join("values.yaml")
 
-	// Read the values.yaml file
-	data, err := os.ReadFile(valuesPath)
+	// Strictly decode and validate the values.yaml file
+	_, report, err := helmcharts.LoadValues[Values](valuesPath)
 	if err != nil {
-		t.Fatalf("Failed to read values.yaml: %v", err)
+		t.Fatalf("Failed to load values.yaml: %v", err)
 	}
-
-	// Parse the YAML
-	var values Values
-	err = yaml.Unmarshal(data, &values)
-	if err != nil {
-		t.Fatalf("Failed to unmarshal values.yaml: %v", err)
-	}
-
-	// Validate the configuration
-	err = values.Validate()
-	if err != nil {
-		t.Errorf("values.yaml validation failed: %v", err)
+	if !report.Valid() {
+		t.Errorf("values.yaml validation failed:\n%s", report)
 	}
 }
 
@@ -162,7 +150,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "valid HPA config",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       1,
 				MaxReplicas:                       3,
 				TargetMemoryUtilizationPercentage: 80,
@@ -172,14 +160,14 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "disabled HPA (no validation required)",
 			config: HPAConfig{
-				Enabled: false,
+				Enabled: boolPtr(false),
 			},
 			wantErr: false,
 		},
 		{
 			name: "zero min replicas",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       0,
 				MaxReplicas:                       3,
 				TargetMemoryUtilizationPercentage: 80,
@@ -189,7 +177,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "max replicas less than min replicas",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       5,
 				MaxReplicas:                       2,
 				TargetMemoryUtilizationPercentage: 80,
@@ -199,7 +187,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "memory utilization percentage too low",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       1,
 				MaxReplicas:                       3,
 				TargetMemoryUtilizationPercentage: 0,
@@ -209,7 +197,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "memory utilization percentage too high",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       1,
 				MaxReplicas:                       3,
 				TargetMemoryUtilizationPercentage: 101,
@@ -219,7 +207,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "valid with equal min and max replicas",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       2,
 				MaxReplicas:                       2,
 				TargetMemoryUtilizationPercentage: 80,
@@ -229,7 +217,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "valid with 100 percent memory utilization",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       1,
 				MaxReplicas:                       3,
 				TargetMemoryUtilizationPercentage: 100,
@@ -239,7 +227,7 @@ func TestHPAConfigValidation(t *testing.T) {
 		{
 			name: "valid with 1 percent memory utilization",
 			config: HPAConfig{
-				Enabled:                           true,
+				Enabled:                           boolPtr(true),
 				MinReplicas:                       1,
 				MaxReplicas:                       3,
 				TargetMemoryUtilizationPercentage: 1,
@@ -267,7 +255,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "valid ClusterIP service",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "ClusterIP",
 				Port:    1323,
 			},
@@ -276,7 +264,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "valid NodePort service",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "NodePort",
 				Port:    1323,
 			},
@@ -285,7 +273,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "valid LoadBalancer service",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "LoadBalancer",
 				Port:    1323,
 			},
@@ -294,14 +282,14 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "disabled service (no validation required)",
 			config: ServiceConfig{
-				Enabled: false,
+				Enabled: boolPtr(false),
 			},
 			wantErr: false,
 		},
 		{
 			name: "enabled service missing port",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "ClusterIP",
 				Port:    0,
 			},
@@ -310,7 +298,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "invalid service type",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "InvalidType",
 				Port:    1323,
 			},
@@ -319,7 +307,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "port too high",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "ClusterIP",
 				Port:    65536,
 			},
@@ -328,7 +316,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "valid max port",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "ClusterIP",
 				Port:    65535,
 			},
@@ -337,7 +325,7 @@ func TestServiceConfigValidation(t *testing.T) {
 		{
 			name: "valid min port",
 			config: ServiceConfig{
-				Enabled: true,
+				Enabled: boolPtr(true),
 				Type:    "ClusterIP",
 				Port:    1,
 			},
@@ -764,6 +752,53 @@ func TestResourceConfigValidation(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "requests equal to limits in other units",
+			resources: ResourceConfig{
+				Limits: ResourceSpec{
+					CPU:    "1",
+					Memory: "1Gi",
+				},
+				Requests: ResourceSpec{
+					CPU:    "1000m",
+					Memory: "1073741824",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "cpu request exceeds limit",
+			resources: ResourceConfig{
+				Limits: ResourceSpec{
+					CPU: "500m",
+				},
+				Requests: ResourceSpec{
+					CPU: "1",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "memory request exceeds limit",
+			resources: ResourceConfig{
+				Limits: ResourceSpec{
+					Memory: "1G",
+				},
+				Requests: ResourceSpec{
+					Memory: "1Gi",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid quantity",
+			resources: ResourceConfig{
+				Requests: ResourceSpec{
+					Memory: "128mb",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -916,7 +951,7 @@ func TestServiceAccountConfigValidation(t *testing.T) {
 		{
 			name: "valid with create true and name",
 			config: ServiceAccountConfig{
-				Create: true,
+				Create: boolPtr(true),
 				Name:   "portal-api",
 			},
 			wantErr: false,
@@ -924,14 +959,14 @@ func TestServiceAccountConfigValidation(t *testing.T) {
 		{
 			name: "valid with create false and no name",
 			config: ServiceAccountConfig{
-				Create: false,
+				Create: boolPtr(false),
 			},
 			wantErr: false,
 		},
 		{
 			name: "valid with annotations",
 			config: ServiceAccountConfig{
-				Create: true,
+				Create: boolPtr(true),
 				Name:   "portal-api",
 				Annotations: map[string]string{
 					"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/my-role",
@@ -942,7 +977,7 @@ func TestServiceAccountConfigValidation(t *testing.T) {
 		{
 			name: "create true missing name",
 			config: ServiceAccountConfig{
-				Create: true,
+				Create: boolPtr(true),
 			},
 			wantErr: true,
 		},
@@ -0,0 +1,186 @@
+package helmcharts_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+	"gopkg.in/yaml.v3"
+
+	// The charts register themselves in helmcharts.DefaultRegistry
+	_ "github.com/tacokumo/helm-charts/charts/portal-controller-kubernetes"
+	_ "github.com/tacokumo/helm-charts/charts/tacokumo-application"
+	_ "github.com/tacokumo/helm-charts/charts/tacokumo-portal"
+	_ "github.com/tacokumo/helm-charts/charts/tacokumo-portal-proxy"
+)
+
+// TestEveryChartIsRegistered fails when a chart directory has no registered
+// Values type, or when the registered type does not validate its values
+func TestEveryChartIsRegistered(t *testing.T) {
+	entries, err := os.ReadDir("charts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join("charts", entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "Chart.yaml")); err != nil {
+			continue
+		}
+		dirs[filepath.ToSlash(dir)] = true
+
+		t.Run(entry.Name(), func(t *testing.T) {
+			c, err := helmcharts.DefaultRegistry.Lookup(entry.Name())
+			if err != nil {
+				t.Fatalf("%s has no registered Values type: %v", dir, err)
+			}
+			if c.Dir != filepath.ToSlash(dir) {
+				t.Errorf("Dir = %q, want %q", c.Dir, dir)
+			}
+
+			// The registered defaults must be the ones on disk and must be valid
+			values, err := os.ReadFile(filepath.Join(dir, "values.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(values) != string(c.ValuesYAML) {
+				t.Errorf("registered values.yaml differs from %s", filepath.Join(dir, "values.yaml"))
+			}
+			_, report, err := c.Validate()
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if !report.Valid() {
+				t.Errorf("default values are invalid:\n%s", report)
+			}
+
+			// Every chart runs at least one image, which must be tagged image:"true"
+			// so that the registry allow-list covers it
+			none, err := helmcharts.NewRegistryAllowList()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, report, err := c.ValidateWith([]helmcharts.Check{none.Check}); err != nil || report.Valid() {
+				t.Errorf("an empty registry allow-list accepts the default values; tag the image fields image:\"true\"")
+			}
+
+			// A Values type that accepts its zero value validates nothing
+			if c.NewValues().Validate() == nil {
+				t.Errorf("%T accepts empty values; its Validate method checks nothing", c.NewValues())
+			}
+		})
+	}
+
+	for _, c := range helmcharts.DefaultRegistry.Charts() {
+		if !dirs[c.Dir] {
+			t.Errorf("chart %q is registered for %s, which is not a chart directory", c.Name, c.Dir)
+		}
+	}
+}
+
+// TestIngressHostnames collects the hosts of a chart's ingress and HTTPRoute
+func TestIngressHostnames(t *testing.T) {
+	c, err := helmcharts.DefaultRegistry.Lookup("tacokumo-application")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ingress and route are disabled by default
+	values, _, err := c.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := helmcharts.IngressHostnames(values); len(got) != 0 {
+		t.Errorf("IngressHostnames() = %q, want none for the default values", got)
+	}
+
+	overlay, err := helmcharts.ParseDocument("prod.yaml", []byte(`main:
+  ingress:
+    enabled: true
+    className: nginx
+    hosts:
+      - host: app.tacokumo.dev
+        paths: [{path: /, pathType: Prefix}]
+      - host: api.tacokumo.dev
+        paths: [{path: /, pathType: Prefix}]
+    tls:
+      - secretName: app-tls
+        hosts: [app.tacokumo.dev, www.tacokumo.dev]
+  route:
+    http:
+      enabled: true
+      hostnames: [gateway.tacokumo.dev, app.tacokumo.dev]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, report, err := c.Validate(overlay)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !report.Valid() {
+		t.Fatalf("overlay is invalid:\n%s", report)
+	}
+
+	want := []string{"api.tacokumo.dev", "app.tacokumo.dev", "gateway.tacokumo.dev", "www.tacokumo.dev"}
+	if got := helmcharts.IngressHostnames(values); !reflect.DeepEqual(got, want) {
+		t.Errorf("IngressHostnames() = %q, want %q", got, want)
+	}
+}
+
+var updateSchema = flag.Bool("update-schema", false, "regenerate the values.schema.json of every chart")
+
+// TestChartValuesTypes fails when a file that each chart keeps in step with
+// its Values type is stale
+func TestChartValuesTypes(t *testing.T) {
+	for _, c := range helmcharts.DefaultRegistry.Charts() {
+		t.Run(c.Name, func(t *testing.T) {
+			t.Run("schema", func(t *testing.T) {
+				want, err := helmcharts.GenerateSchema(c.NewValues())
+				if err != nil {
+					t.Fatalf("Failed to generate values schema: %v", err)
+				}
+
+				schemaPath := filepath.Join(c.Dir, "values.schema.json")
+				if *updateSchema {
+					if err := os.WriteFile(schemaPath, want, 0o644); err != nil {
+						t.Fatalf("Failed to write %s: %v", schemaPath, err)
+					}
+				}
+
+				got, err := os.ReadFile(schemaPath)
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", schemaPath, err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s is stale; run `make schema` to regenerate it", schemaPath)
+				}
+			})
+
+			t.Run("defaults", func(t *testing.T) {
+				want := c.NewValues()
+				if err := yaml.Unmarshal(c.ValuesYAML, want); err != nil {
+					t.Fatalf("Failed to unmarshal %s: %v", c.ValuesFile(), err)
+				}
+
+				got := c.NewValues()
+				if err := helmcharts.ApplyDefaults(got); err != nil {
+					t.Fatalf("ApplyDefaults() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					gotYAML, _ := yaml.Marshal(got)
+					wantYAML, _ := yaml.Marshal(want)
+					t.Errorf("ApplyDefaults(%T) does not match %s\ngot:\n%s\nwant:\n%s", got, c.ValuesFile(), gotYAML, wantYAML)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package helmcharts
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Check is an additional validation of decoded values, such as
+// RegistryAllowList.Check or StrictSecrets. Its failures are reported like
+// those of Validate.
+type Check func(values any) error
+
+// walkValues calls visit with every value reachable from v, stored in field,
+// together with the tag of the struct field holding it; list items and map
+// values inherit the tag of their list or map. The children of a value are not
+// visited when visit returns true.
+func walkValues(field string, v reflect.Value, tag reflect.StructTag, visit func(field string, v reflect.Value, tag reflect.StructTag) bool) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkValues(field, v.Elem(), tag, visit)
+		}
+		return
+	case reflect.Invalid:
+		return
+	}
+	if visit(field, v, tag) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := f.Name
+			if f.Anonymous {
+				name = ""
+			}
+			walkValues(joinField(field, name), v.Field(i), f.Tag, visit)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkValues(fmt.Sprintf("%s[%d]", field, i), v.Index(i), tag, visit)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkValues(fmt.Sprintf("%s[%v]", field, iter.Key()), iter.Value(), tag, visit)
+		}
+	}
+}
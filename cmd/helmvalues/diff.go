@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+// runDiff implements the diff command, which prints how the values of two
+// files differ once each is merged onto the chart defaults
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	chartName := fs.String("chart", "", "name of the chart whose values are compared")
+	output := fs.String("output", "text", "output format: text or json")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(stderr, "helmvalues: diff needs the old and the new values file")
+		return exitUsage
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "helmvalues: unknown output format %q\n", *output)
+		return exitUsage
+	}
+	c, err := helmcharts.DefaultRegistry.Lookup(*chartName)
+	if err != nil {
+		fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+		return exitUsage
+	}
+
+	docs := make([]*helmcharts.Document, 2)
+	for i, file := range fs.Args() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+			return exitUsage
+		}
+		if docs[i], err = helmcharts.ParseDocument(file, data); err != nil {
+			fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+			return exitInvalid
+		}
+	}
+	changes, err := c.Diff(docs[0], docs[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+		return exitInvalid
+	}
+
+	if *output == "json" {
+		if changes == nil {
+			changes = []helmcharts.Change{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(changes); err != nil {
+			fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+			return exitUsage
+		}
+		return exitOK
+	}
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	return exitOK
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRunDiff(t *testing.T) {
+	before := writeFile(t, "old.yaml", `main:
+  hpa:
+    maxReplicas: 3
+`)
+	after := writeFile(t, "new.yaml", `main:
+  hpa:
+    maxReplicas: 10
+  service:
+    ports:
+      - name: http
+        port: 80
+      - name: https
+        port: 443
+`)
+	broken := writeFile(t, "broken.yaml", "main:\n  hpa:\n    maxReplicas: ten\n")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:     "text",
+			args:     []string{"diff", "--chart", "tacokumo-application", before, after},
+			wantCode: exitOK,
+			// The new file replaces the ports of values.yaml, dropping the protocol
+			wantStdout: `main.hpa.maxReplicas: 3 → 10
+main.service.ports[name=http].protocol: TCP → ""
+main.service.ports[name=https]: added {name: https, port: 443}
+`,
+		},
+		{
+			name:     "no changes",
+			args:     []string{"diff", "--chart", "tacokumo-application", before, before},
+			wantCode: exitOK,
+		},
+		{
+			name:       "undecodable file",
+			args:       []string{"diff", "--chart", "tacokumo-application", before, broken},
+			wantCode:   exitInvalid,
+			wantStderr: "cannot unmarshal",
+		},
+		{
+			name:       "missing file argument",
+			args:       []string{"diff", "--chart", "tacokumo-application", before},
+			wantCode:   exitUsage,
+			wantStderr: "needs the old and the new values file",
+		},
+		{
+			name:       "unknown chart",
+			args:       []string{"diff", "--chart", "tacokumo-admin", before, after},
+			wantCode:   exitUsage,
+			wantStderr: `unknown chart "tacokumo-admin"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d\nstderr:\n%s", code, tt.wantCode, &stderr)
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout =\n%s\nwant\n%s", &stdout, tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", &stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunDiffJSON(t *testing.T) {
+	before := writeFile(t, "old.yaml", "main:\n  hpa:\n    maxReplicas: 3\n")
+	after := writeFile(t, "new.yaml", "main:\n  hpa:\n    maxReplicas: 10\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"diff", "--chart", "tacokumo-application", "-o", "json", before, after}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("run() = %d\nstderr:\n%s", code, &stderr)
+	}
+	var changes []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+		Old  int    `json:"old"`
+		New  int    `json:"new"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &changes); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, &stdout)
+	}
+	if len(changes) != 1 || changes[0].Path != "main.hpa.maxReplicas" || changes[0].Kind != "changed" || changes[0].Old != 3 || changes[0].New != 10 {
+		t.Errorf("changes = %+v", changes)
+	}
+}
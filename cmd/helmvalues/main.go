@@ -0,0 +1,196 @@
+// Command helmvalues validates values files against the Go types of the
+// tacokumo Helm charts, merging them onto the chart defaults as helm does.
+//
+// Usage:
+//
+//	helmvalues validate --chart tacokumo-application -f values.yaml -f prod.yaml --set main.image=nginx:1.27
+//	helmvalues validate --chart tacokumo-portal -f prod.yaml --allow-registry 'ghcr.io/tacokumo/*'
+//	helmvalues diff --chart tacokumo-application old/values.yaml new/values.yaml
+//
+// validate exits with 1 when the values are invalid and with 2 on usage errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+
+	// The charts register themselves in helmcharts.DefaultRegistry
+	_ "github.com/tacokumo/helm-charts/charts/portal-controller-kubernetes"
+	_ "github.com/tacokumo/helm-charts/charts/tacokumo-application"
+	_ "github.com/tacokumo/helm-charts/charts/tacokumo-portal"
+	_ "github.com/tacokumo/helm-charts/charts/tacokumo-portal-proxy"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "validate":
+		return runValidate(args[1:], stdout, stderr)
+	case "diff":
+		return runDiff(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "helmvalues: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, `Usage:
+  helmvalues validate --chart NAME [-f FILE]... [--set EXPR]... [--set-string EXPR]... [--set-json EXPR]... [--allow-registry GLOB]... [--output text|json|github]
+  helmvalues diff --chart NAME [--output text|json] OLD NEW
+
+Charts: %s
+`, strings.Join(chartNames(), ", "))
+}
+
+func chartNames() []string {
+	var names []string
+	for _, c := range helmcharts.DefaultRegistry.Charts() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// stringList is a flag that can be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// runValidate implements the validate command
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		usage(stderr)
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+
+	var files, sets, setStrings, setJSONs, registries stringList
+	chartName := fs.String("chart", "", "name of the chart whose values are validated")
+	output := fs.String("output", "text", "output format: text, json or github")
+	fs.StringVar(output, "o", "text", "shorthand for --output")
+	fs.Var(&files, "f", "values file merged onto the chart defaults (can be repeated)")
+	fs.Var(&files, "values", "same as -f")
+	fs.Var(&sets, "set", "set values on the command line (can be repeated)")
+	fs.Var(&setStrings, "set-string", "set STRING values on the command line (can be repeated)")
+	fs.Var(&setJSONs, "set-json", "set JSON values on the command line (can be repeated)")
+	fs.Var(&registries, "allow-registry", "only allow images whose registry/repository matches this glob, e.g. ghcr.io/tacokumo/* (can be repeated)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "helmvalues: unexpected arguments %q\n", fs.Args())
+		return exitUsage
+	}
+
+	if *chartName == "" {
+		fmt.Fprintln(stderr, "helmvalues: --chart is required")
+		usage(stderr)
+		return exitUsage
+	}
+	c, err := helmcharts.DefaultRegistry.Lookup(*chartName)
+	if err != nil {
+		fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+		return exitUsage
+	}
+	format, ok := formats[*output]
+	if !ok {
+		fmt.Fprintf(stderr, "helmvalues: unknown output format %q\n", *output)
+		return exitUsage
+	}
+	var checks []helmcharts.Check
+	if len(registries) > 0 {
+		allowList, err := helmcharts.NewRegistryAllowList(registries...)
+		if err != nil {
+			fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+			return exitUsage
+		}
+		checks = append(checks, allowList.Check)
+	}
+
+	var docs []*helmcharts.Document
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+			return exitUsage
+		}
+		doc, err := helmcharts.ParseDocument(file, data)
+		if err != nil {
+			return report(stdout, stderr, format, c, nil, err)
+		}
+		docs = append(docs, doc)
+	}
+	// helm applies --set-json, --set and --set-string in this order, after -f
+	for _, flags := range []struct {
+		kind  helmcharts.SetKind
+		exprs []string
+	}{
+		{helmcharts.SetJSONFlag, setJSONs},
+		{helmcharts.SetFlag, sets},
+		{helmcharts.SetStringFlag, setStrings},
+	} {
+		for _, expr := range flags.exprs {
+			doc, err := helmcharts.ParseSet(flags.kind, expr)
+			if err != nil {
+				fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+				return exitUsage
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	_, r, err := c.ValidateWith(checks, docs...)
+	return report(stdout, stderr, format, c, r, err)
+}
+
+// report writes the result in the selected format and returns the exit code.
+// A values file that cannot be parsed or decoded is reported as an issue.
+func report(stdout, stderr io.Writer, format formatter, c *helmcharts.Chart, r *helmcharts.ValidationReport, err error) int {
+	if err != nil {
+		r = &helmcharts.ValidationReport{Issues: []helmcharts.ValidationIssue{{Message: err.Error()}}}
+	}
+	if err := format(stdout, c, r); err != nil {
+		fmt.Fprintf(stderr, "helmvalues: %v\n", err)
+		return exitUsage
+	}
+	if !r.Valid() {
+		return exitInvalid
+	}
+	return exitOK
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunValidate(t *testing.T) {
+	valid := writeFile(t, "valid.yaml", "main:\n  image: nginx:1.27\n")
+	invalid := writeFile(t, "invalid.yaml", "main:\n  hpa:\n    minReplicas: 0\n")
+	broken := writeFile(t, "broken.yaml", "main:\n  hpa:\n    minReplicas: one\n")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+		wantStderr string
+	}{
+		{
+			name:     "valid values",
+			args:     []string{"validate", "--chart", "tacokumo-application", "-f", valid},
+			wantCode: exitOK,
+		},
+		{
+			name:     "every chart validates its own defaults",
+			args:     []string{"validate", "--chart", "tacokumo-portal-proxy"},
+			wantCode: exitOK,
+		},
+		{
+			name:       "invalid values file",
+			args:       []string{"validate", "--chart", "tacokumo-application", "-f", valid, "-f", invalid},
+			wantCode:   exitInvalid,
+			wantStdout: []string{invalid + ":3:5: main.hpa.minReplicas: must be at least 1 (got 0)"},
+		},
+		{
+			name:     "set flags are applied after files",
+			args:     []string{"validate", "--chart", "tacokumo-application", "-f", invalid, "--set", "main.hpa.minReplicas=1"},
+			wantCode: exitOK,
+		},
+		{
+			name:       "set-string keeps strings",
+			args:       []string{"validate", "--chart", "tacokumo-application", "--set-string", "main.hpa.minReplicas=2"},
+			wantCode:   exitInvalid,
+			wantStdout: []string{"--set-string: yaml: unmarshal errors"},
+		},
+		{
+			name:     "set-json is applied before set",
+			args:     []string{"validate", "--chart", "tacokumo-application", "--set", "main.hpa.maxReplicas=3", "--set-json", `main.hpa={"minReplicas":2,"maxReplicas":1,"targetMemoryUtilizationPercentage":75}`},
+			wantCode: exitOK,
+		},
+		{
+			name:       "undecodable file",
+			args:       []string{"validate", "--chart", "tacokumo-application", "-f", broken},
+			wantCode:   exitInvalid,
+			wantStdout: []string{broken + ": yaml: unmarshal errors"},
+		},
+		{
+			name:       "missing chart",
+			args:       []string{"validate", "-f", valid},
+			wantCode:   exitUsage,
+			wantStderr: "--chart is required",
+		},
+		{
+			name:       "unknown chart",
+			args:       []string{"validate", "--chart", "tacokumo-admin"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown chart "tacokumo-admin"`,
+		},
+		{
+			name:       "unknown format",
+			args:       []string{"validate", "--chart", "tacokumo-portal", "-o", "xml"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown output format "xml"`,
+		},
+		{
+			name:       "missing file",
+			args:       []string{"validate", "--chart", "tacokumo-portal", "-f", filepath.Join(t.TempDir(), "missing.yaml")},
+			wantCode:   exitUsage,
+			wantStderr: "no such file or directory",
+		},
+		{
+			name:       "invalid set expression",
+			args:       []string{"validate", "--chart", "tacokumo-portal", "--set", "main.image"},
+			wantCode:   exitUsage,
+			wantStderr: `key "image" has no value`,
+		},
+		{
+			name:     "allowed registry",
+			args:     []string{"validate", "--chart", "tacokumo-portal", "--allow-registry", "ghcr.io/tacokumo/*"},
+			wantCode: exitOK,
+		},
+		{
+			name:       "image from a registry that is not allowed",
+			args:       []string{"validate", "--chart", "tacokumo-portal-proxy", "--allow-registry", "ghcr.io/tacokumo/*", "--allow-registry", "mirror.example.com/**"},
+			wantCode:   exitInvalid,
+			wantStdout: []string{"portalProxy.image.repository: image docker.io/library/caddy is not from an allowed registry; allowed are ghcr.io/tacokumo/*, mirror.example.com/**"},
+		},
+		{
+			name:       "invalid registry pattern",
+			args:       []string{"validate", "--chart", "tacokumo-portal", "--allow-registry", "ghcr.io/[tacokumo"},
+			wantCode:   exitUsage,
+			wantStderr: `invalid registry pattern "ghcr.io/[tacokumo"`,
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"lint"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown command "lint"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d\nstdout:\n%s\nstderr:\n%s", code, tt.wantCode, &stdout, &stderr)
+			}
+			for _, want := range tt.wantStdout {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want it to contain %q", &stdout, want)
+				}
+			}
+			if len(tt.wantStdout) == 0 && tt.wantCode == exitOK && stdout.Len() > 0 {
+				t.Errorf("stdout = %q, want no output", &stdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", &stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunValidatesEveryChartsDefaults(t *testing.T) {
+	for _, name := range chartNames() {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{"validate", "--chart", name}, &stdout, &stderr); code != exitOK {
+				t.Errorf("run() = %d\nstdout:\n%s\nstderr:\n%s", code, &stdout, &stderr)
+			}
+		})
+	}
+}
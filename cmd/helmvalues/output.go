@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+// formatter writes a validation report for a chart
+type formatter func(w io.Writer, c *helmcharts.Chart, r *helmcharts.ValidationReport) error
+
+var formats = map[string]formatter{
+	"text":   writeText,
+	"json":   writeJSON,
+	"github": writeGitHub,
+}
+
+// writeText writes one issue per line, as file:line:column: path: message
+func writeText(w io.Writer, _ *helmcharts.Chart, r *helmcharts.ValidationReport) error {
+	for _, issue := range r.Issues {
+		if _, err := fmt.Fprintln(w, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonReport is the document written by the json format
+type jsonReport struct {
+	Chart   string                       `json:"chart"`
+	Version string                       `json:"version"`
+	Valid   bool                         `json:"valid"`
+	Issues  []helmcharts.ValidationIssue `json:"issues"`
+}
+
+// writeJSON writes the report as a single JSON document
+func writeJSON(w io.Writer, c *helmcharts.Chart, r *helmcharts.ValidationReport) error {
+	out := jsonReport{Chart: c.Name, Version: c.Version, Valid: r.Valid(), Issues: r.Issues}
+	if out.Issues == nil {
+		out.Issues = []helmcharts.ValidationIssue{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// writeGitHub writes the issues as GitHub Actions error annotations
+func writeGitHub(w io.Writer, c *helmcharts.Chart, r *helmcharts.ValidationReport) error {
+	for _, issue := range r.Issues {
+		var props []string
+		// Issues in --set flags and in the chart defaults have no file to annotate
+		if issue.File != "" && !strings.HasPrefix(issue.File, "--") && issue.File != c.ValuesFile() {
+			props = append(props, "file="+escapeProperty(issue.File))
+			if issue.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", issue.Line), fmt.Sprintf("col=%d", issue.Column))
+			}
+		}
+		title := c.Name
+		if issue.Path != "" {
+			title += ": " + issue.Path
+		}
+		props = append(props, "title="+escapeProperty(title))
+
+		msg := issue.Message
+		if issue.Path != "" {
+			msg = issue.Path + ": " + msg
+		}
+		if _, err := fmt.Fprintf(w, "::error %s::%s\n", strings.Join(props, ","), escapeData(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+var outputTestChart = &helmcharts.Chart{Name: "tacokumo-application", Version: "0.3.0", Dir: "charts/tacokumo-application"}
+
+var outputTestReport = &helmcharts.ValidationReport{Issues: []helmcharts.ValidationIssue{
+	{Path: "main.hpa.minReplicas", File: "prod.yaml", Line: 3, Column: 5, Tag: "min", Param: "1", Value: 0, Message: "must be at least 1 (got 0)"},
+	{Path: "main.image", File: "--set", Tag: "required", Message: "is required"},
+	{Path: "main.ingress.className", File: "charts/tacokumo-application/values.yaml", Line: 29, Column: 5, Message: "is required when enabled is true"},
+	{Message: "a.yaml: yaml: line 2: mapping values are not allowed in this context\n50% done"},
+}}
+
+func TestWriteText(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeText(&b, outputTestChart, outputTestReport); err != nil {
+		t.Fatal(err)
+	}
+	want := `prod.yaml:3:5: main.hpa.minReplicas: must be at least 1 (got 0)
+--set: main.image: is required
+charts/tacokumo-application/values.yaml:29:5: main.ingress.className: is required when enabled is true
+a.yaml: yaml: line 2: mapping values are not allowed in this context
+50% done
+`
+	if b.String() != want {
+		t.Errorf("writeText() =\n%s\nwant\n%s", &b, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		report *helmcharts.ValidationReport
+		valid  bool
+		issues int
+	}{
+		{name: "issues", report: outputTestReport, valid: false, issues: 4},
+		{name: "valid", report: &helmcharts.ValidationReport{}, valid: true, issues: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := writeJSON(&b, outputTestChart, tt.report); err != nil {
+				t.Fatal(err)
+			}
+			var got struct {
+				Chart   string            `json:"chart"`
+				Version string            `json:"version"`
+				Valid   bool              `json:"valid"`
+				Issues  []json.RawMessage `json:"issues"`
+			}
+			if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+				t.Fatalf("writeJSON() wrote invalid JSON: %v\n%s", err, &b)
+			}
+			if got.Chart != "tacokumo-application" || got.Version != "0.3.0" || got.Valid != tt.valid || got.Issues == nil || len(got.Issues) != tt.issues {
+				t.Errorf("writeJSON() =\n%s", &b)
+			}
+		})
+	}
+}
+
+func TestWriteGitHub(t *testing.T) {
+	var b bytes.Buffer
+	if err := writeGitHub(&b, outputTestChart, outputTestReport); err != nil {
+		t.Fatal(err)
+	}
+	want := `::error file=prod.yaml,line=3,col=5,title=tacokumo-application%3A main.hpa.minReplicas::main.hpa.minReplicas: must be at least 1 (got 0)
+::error title=tacokumo-application%3A main.image::main.image: is required
+::error title=tacokumo-application%3A main.ingress.className::main.ingress.className: is required when enabled is true
+::error title=tacokumo-application::a.yaml: yaml: line 2: mapping values are not allowed in this context%0A50%25 done
+`
+	if b.String() != want {
+		t.Errorf("writeGitHub() =\n%s\nwant\n%s", &b, want)
+	}
+}
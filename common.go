@@ -1,9 +1,5 @@
 package helmcharts
 
-import (
-	"github.com/go-playground/validator/v10"
-)
-
 // Image represents container image configuration
 type Image struct {
 	Repository string `yaml:"repository" validate:"required"`
@@ -189,27 +185,55 @@ type Toleration struct {
 
 // Validate validates the common structs using the validator
 func (i *Image) Validate() error {
-	return validator.New().Struct(i)
+	return ValidateStruct(i)
 }
 
+// Validate validates the Resources, including that requests do not exceed limits
 func (r *Resources) Validate() error {
-	return validator.New().Struct(r)
+	var errs Errors
+	errs.Struct(r)
+	errs.RequestWithinLimit("Requests.CPU", r.Requests.CPU, r.Limits.CPU)
+	errs.RequestWithinLimit("Requests.Memory", r.Requests.Memory, r.Limits.Memory)
+	return errs.Err()
 }
 
 func (p *HTTPProbe) Validate() error {
-	return validator.New().Struct(p)
+	return ValidateStruct(p)
 }
 
 func (s *SecurityContext) Validate() error {
-	return validator.New().Struct(s)
+	return ValidateStruct(s)
 }
 
 func (s *Service) Validate() error {
-	return validator.New().Struct(s)
+	return ValidateStruct(s)
 }
 
 func (i *Ingress) Validate() error {
-	return validator.New().Struct(i)
+	return ValidateStruct(i)
+}
+
+// RoutedHostnames returns the hosts and TLS hosts of the Ingress when it is enabled
+func (i *Ingress) RoutedHostnames() []string {
+	if !i.Enabled {
+		return nil
+	}
+	var hosts []string
+	for _, h := range i.Hosts {
+		hosts = append(hosts, h.Host)
+	}
+	for _, t := range i.TLS {
+		hosts = append(hosts, t.Hosts...)
+	}
+	return hosts
+}
+
+// RoutedHostnames returns the hostnames of the HTTPRoute when it is enabled
+func (h *HTTPRoute) RoutedHostnames() []string {
+	if !h.Enabled {
+		return nil
+	}
+	return h.Hostnames
 }
 
 // HTTPRoute represents Gateway API HTTPRoute configuration
@@ -250,5 +274,5 @@ type HTTPRouteHeader struct {
 }
 
 func (h *HTTPRoute) Validate() error {
-	return validator.New().Struct(h)
+	return ValidateStruct(h)
 }
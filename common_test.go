@@ -1,6 +1,7 @@
 package helmcharts
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -394,7 +395,10 @@ func TestCustomValidators(t *testing.T) {
 		{"valid CPU cores", "0.5", false},
 		{"valid memory Mi", "256Mi", false},
 		{"valid memory Gi", "1Gi", false},
+		{"valid exponent", "1e3", false},
 		{"invalid format", "invalid", true},
+		{"invalid lowercase suffix", "1g", true},
+		{"invalid negative", "-100m", true},
 		{"empty string", "", false}, // Should be valid for omitempty
 	}
 
@@ -465,4 +469,51 @@ func TestCustomValidators(t *testing.T) {
 			}
 		})
 	}
+
+	// Test host:port validation
+	hostPortTests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{"valid host name", "redis-0.redis:6379", false},
+		{"valid IPv4", "10.0.0.1:26379", false},
+		{"valid IPv6", "[2001:db8::1]:6379", false},
+		{"missing port", "redis-0.redis", true},
+		{"port out of range", "redis:65536", true},
+		{"IPv6 without brackets", "2001:db8::1:6379", true},
+		{"invalid host name", "redis_0:6379", true},
+		{"empty string", "", false}, // Should be valid for omitempty
+	}
+
+	type TestHostPort struct {
+		Addr string `validate:"host_port"`
+	}
+
+	for _, tt := range hostPortTests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := TestHostPort{Addr: tt.addr}
+			err := v.Struct(test)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Host port validation error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResourcesValidateRequestsWithinLimits(t *testing.T) {
+	resources := Resources{
+		Requests: ResourceRequests{CPU: "2", Memory: "128Mi"},
+		Limits:   ResourceLimits{CPU: "1500m", Memory: "128Mi"},
+	}
+
+	err := resources.Validate()
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Resources validation error = %v, want one error", err)
+	}
+	var fe *FieldError
+	if !errors.As(errs[0], &fe) || fe.Field != "Requests.CPU" || fe.Tag != "lte_limit" {
+		t.Errorf("error = %v, want lte_limit failure on Requests.CPU", errs[0])
+	}
 }
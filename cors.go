@@ -0,0 +1,181 @@
+package helmcharts
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// schemePattern matches a lower case URL scheme (RFC 3986)
+var schemePattern = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+
+// defaultPorts are the ports browsers leave out of the Origin header
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// CORSOriginError describes why an allowed origin is malformed
+type CORSOriginError struct {
+	Origin string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *CORSOriginError) Error() string {
+	return fmt.Sprintf("invalid CORS origin %q: %s", e.Origin, e.Reason)
+}
+
+// CORSOrigin represents an allowed origin of a CORS policy: * for any
+// origin, scheme://host[:port] as browsers send it in the Origin header, or
+// scheme://*.domain[:port] for any subdomain of domain
+type CORSOrigin struct {
+	// Any is set for *, which allows every origin
+	Any bool
+
+	Scheme string
+	Host   string
+	Port   string
+
+	// Subdomains is set for *.Host, which allows the subdomains of Host at any
+	// depth but not Host itself
+	Subdomains bool
+}
+
+// ParseCORSOrigin parses an allowed origin. Origins are compared as strings by
+// browsers and servers, so anything that can never equal an Origin header is
+// rejected: paths, queries, upper case letters and default ports.
+func ParseCORSOrigin(origin string) (CORSOrigin, error) {
+	invalid := func(format string, args ...any) (CORSOrigin, error) {
+		return CORSOrigin{}, &CORSOriginError{Origin: origin, Reason: fmt.Sprintf(format, args...)}
+	}
+	if origin == "*" {
+		return CORSOrigin{Any: true}, nil
+	}
+
+	scheme, rest, ok := strings.Cut(origin, "://")
+	if !ok || scheme == "" {
+		return invalid("must be * or scheme://host[:port], e.g. https://portal.tacokumo.dev")
+	}
+	if !schemePattern.MatchString(scheme) {
+		return invalid("scheme %q must be lower case letters, digits, +, - and .", scheme)
+	}
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		return invalid("must not have a path, query or fragment; browsers send scheme://host[:port] only")
+	}
+	if strings.Contains(rest, "@") {
+		return invalid("must not have user information")
+	}
+
+	o := CORSOrigin{Scheme: scheme, Host: rest}
+	if host, port, err := net.SplitHostPort(rest); err == nil {
+		o.Host, o.Port = host, port
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return invalid("port %q must be a number between 1 and 65535", port)
+		}
+		if port == defaultPorts[scheme] {
+			return invalid("must leave out the default port %s, which browsers do not send", port)
+		}
+	} else if strings.HasPrefix(rest, "[") && strings.HasSuffix(rest, "]") {
+		o.Host = rest[1 : len(rest)-1]
+	}
+	if name, ok := strings.CutPrefix(o.Host, "*."); ok {
+		if strings.Count(name, ".") < 1 {
+			return invalid("wildcard must be followed by a domain with at least two labels, e.g. *.tacokumo.dev")
+		}
+		o.Host, o.Subdomains = name, true
+	}
+
+	switch {
+	case o.Host == "":
+		return invalid("host is empty")
+	case net.ParseIP(o.Host) != nil:
+		if o.Subdomains {
+			return invalid("wildcard must be followed by a domain, not an IP address")
+		}
+		if strings.Contains(o.Host, ":") && !strings.HasPrefix(rest, "[") {
+			return invalid("IPv6 address must be enclosed in brackets")
+		}
+	case !hostnamePattern.MatchString(o.Host):
+		if strings.Contains(o.Host, "*") {
+			return invalid("wildcard is only allowed as the first label, e.g. https://*.tacokumo.dev")
+		}
+		return invalid("host %q must be a host name or an IP address", o.Host)
+	case strings.ToLower(o.Host) != o.Host:
+		return invalid("host must be lower case, as browsers send it")
+	}
+	return o, nil
+}
+
+// String returns the origin as written in the config
+func (o CORSOrigin) String() string {
+	if o.Any {
+		return "*"
+	}
+	host := o.Host
+	if o.Subdomains {
+		host = "*." + host
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if o.Port != "" {
+		host += ":" + o.Port
+	}
+	return o.Scheme + "://" + host
+}
+
+// Matches reports whether a request with the given Origin header is allowed by o
+func (o CORSOrigin) Matches(origin string) bool {
+	if o.Any {
+		return true
+	}
+	req, err := ParseCORSOrigin(origin)
+	if err != nil || req.Any || req.Subdomains || req.Scheme != o.Scheme || req.Port != o.Port {
+		return false
+	}
+	if o.Subdomains {
+		return strings.HasSuffix(req.Host, "."+o.Host)
+	}
+	return req.Host == o.Host
+}
+
+// AllowsOrigin reports whether a request with the given Origin header is
+// allowed by any of the AllowedOrigins
+func (c *CORSConfig) AllowsOrigin(origin string) bool {
+	return slices.ContainsFunc(c.AllowedOrigins, func(allowed string) bool {
+		o, err := ParseCORSOrigin(allowed)
+		return err == nil && o.Matches(origin)
+	})
+}
+
+// validateCredentials enforces the rules browsers apply to credentialed
+// requests, which ignore * in Access-Control-Allow-Origin, -Allow-Headers and
+// -Expose-Headers; a wildcard origin must also be the only one
+func (c *CORSConfig) validateCredentials(errs *Errors) {
+	if i := slices.Index(c.AllowedOrigins, "*"); i >= 0 {
+		switch {
+		case c.AllowCredentials:
+			errs.Add(fmt.Sprintf("AllowedOrigins[%d]", i), "cors_credentials", "must not be * when allowCredentials is true; browsers refuse credentialed responses for any origin, so list the origins")
+		case len(c.AllowedOrigins) > 1:
+			errs.Add(fmt.Sprintf("AllowedOrigins[%d]", i), "cors_origin", "must be the only origin when it is *, which already allows every origin")
+		}
+	}
+	if !c.AllowCredentials {
+		return
+	}
+	for _, list := range []struct {
+		field   string
+		headers []string
+	}{
+		{"AllowedHeaders", c.AllowedHeaders},
+		{"ExposedHeaders", c.ExposedHeaders},
+	} {
+		if i := slices.Index(list.headers, "*"); i >= 0 {
+			errs.Add(fmt.Sprintf("%s[%d]", list.field, i), "cors_credentials", "must not be * when allowCredentials is true; browsers read it as a header named *, so list the headers")
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package helmcharts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCORSOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   CORSOrigin
+	}{
+		{origin: "*", want: CORSOrigin{Any: true}},
+		{origin: "https://portal.tacokumo.dev", want: CORSOrigin{Scheme: "https", Host: "portal.tacokumo.dev"}},
+		{origin: "http://localhost:3000", want: CORSOrigin{Scheme: "http", Host: "localhost", Port: "3000"}},
+		{origin: "https://*.tacokumo.dev", want: CORSOrigin{Scheme: "https", Host: "tacokumo.dev", Subdomains: true}},
+		{origin: "https://*.tacokumo.dev:8443", want: CORSOrigin{Scheme: "https", Host: "tacokumo.dev", Port: "8443", Subdomains: true}},
+		{origin: "http://10.0.0.1:8080", want: CORSOrigin{Scheme: "http", Host: "10.0.0.1", Port: "8080"}},
+		{origin: "http://[2001:db8::1]", want: CORSOrigin{Scheme: "http", Host: "2001:db8::1"}},
+		{origin: "capacitor://localhost", want: CORSOrigin{Scheme: "capacitor", Host: "localhost"}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCORSOrigin(tt.origin)
+		if err != nil {
+			t.Errorf("ParseCORSOrigin(%q) error = %v", tt.origin, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCORSOrigin(%q) = %+v, want %+v", tt.origin, got, tt.want)
+		}
+		if got.String() != tt.origin {
+			t.Errorf("ParseCORSOrigin(%q).String() = %q", tt.origin, got.String())
+		}
+	}
+}
+
+func TestParseCORSOriginErrors(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   string
+	}{
+		{origin: "portal.tacokumo.dev", want: "must be * or scheme://host[:port]"},
+		{origin: "https://a.com/path?x", want: "must not have a path, query or fragment"},
+		{origin: "https://portal.tacokumo.dev/", want: "must not have a path, query or fragment"},
+		{origin: "HTTPS://portal.tacokumo.dev", want: "must be lower case"},
+		{origin: "https://Portal.tacokumo.dev", want: "host must be lower case"},
+		{origin: "https://portal.tacokumo.dev:443", want: "must leave out the default port 443"},
+		{origin: "https://portal.tacokumo.dev:0", want: "must be a number between 1 and 65535"},
+		{origin: "https://user@portal.tacokumo.dev", want: "must not have user information"},
+		{origin: "https://*.dev", want: "at least two labels"},
+		{origin: "https://portal.*.tacokumo.dev", want: "wildcard is only allowed as the first label"},
+		{origin: "https://*", want: "wildcard is only allowed as the first label"},
+		{origin: "https://*.10.0.0.1", want: "wildcard must be followed by a domain, not an IP address"},
+		{origin: "https://", want: "host is empty"},
+		{origin: "https://portal_tacokumo.dev", want: "must be a host name or an IP address"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCORSOrigin(tt.origin)
+		var coe *CORSOriginError
+		if !errors.As(err, &coe) || !strings.Contains(coe.Reason, tt.want) {
+			t.Errorf("ParseCORSOrigin(%q) error = %v, want a reason containing %q", tt.origin, err, tt.want)
+		}
+	}
+}
+
+func TestCORSOriginMatches(t *testing.T) {
+	tests := []struct {
+		allowed string
+		origin  string
+		want    bool
+	}{
+		{allowed: "*", origin: "https://evil.example", want: true},
+		{allowed: "https://portal.tacokumo.dev", origin: "https://portal.tacokumo.dev", want: true},
+		{allowed: "https://portal.tacokumo.dev", origin: "http://portal.tacokumo.dev", want: false},
+		{allowed: "https://portal.tacokumo.dev", origin: "https://portal.tacokumo.dev:8443", want: false},
+		{allowed: "https://*.tacokumo.dev", origin: "https://portal.tacokumo.dev", want: true},
+		{allowed: "https://*.tacokumo.dev", origin: "https://a.b.tacokumo.dev", want: true},
+		{allowed: "https://*.tacokumo.dev", origin: "https://tacokumo.dev", want: false},
+		{allowed: "https://*.tacokumo.dev", origin: "https://eviltacokumo.dev", want: false},
+		{allowed: "https://*.tacokumo.dev", origin: "null", want: false},
+	}
+
+	for _, tt := range tests {
+		o, err := ParseCORSOrigin(tt.allowed)
+		if err != nil {
+			t.Fatalf("ParseCORSOrigin(%q) error = %v", tt.allowed, err)
+		}
+		if got := o.Matches(tt.origin); got != tt.want {
+			t.Errorf("%s.Matches(%q) = %v, want %v", tt.allowed, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSConfigValidate(t *testing.T) {
+	valid := func() CORSConfig {
+		return CORSConfig{
+			Enabled:        true,
+			AllowedOrigins: []string{"https://portal.tacokumo.dev", "https://*.tacokumo.dev"},
+			AllowedMethods: []string{"GET", "POST"},
+			AllowedHeaders: []string{"Content-Type", "X-Request-Id"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *CORSConfig)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *CORSConfig) {},
+		},
+		{
+			name:   "credentials with subdomain wildcard",
+			modify: func(c *CORSConfig) { c.AllowCredentials = true },
+		},
+		{
+			name:   "any origin without credentials",
+			modify: func(c *CORSConfig) { c.AllowedOrigins = []string{"*"}; c.AllowedHeaders = []string{"*"} },
+		},
+		{
+			name:   "origin with a path",
+			modify: func(c *CORSConfig) { c.AllowedOrigins = []string{"https://a.com/path?x"} },
+			want:   []string{"AllowedOrigins[0]: must be a CORS origin: must not have a path"},
+		},
+		{
+			name:   "credentials with any origin",
+			modify: func(c *CORSConfig) { c.AllowedOrigins = []string{"*"}; c.AllowCredentials = true },
+			want:   []string{"AllowedOrigins[0]: must not be * when allowCredentials is true"},
+		},
+		{
+			name: "credentials with any header",
+			modify: func(c *CORSConfig) {
+				c.AllowCredentials = true
+				c.AllowedHeaders = []string{"Content-Type", "*"}
+				c.ExposedHeaders = []string{"*"}
+			},
+			want: []string{
+				"AllowedHeaders[1]: must not be * when allowCredentials is true",
+				"ExposedHeaders[0]: must not be * when allowCredentials is true",
+			},
+		},
+		{
+			name:   "any origin among others",
+			modify: func(c *CORSConfig) { c.AllowedOrigins = append(c.AllowedOrigins, "*") },
+			want:   []string{"AllowedOrigins[2]: must be the only origin when it is *"},
+		},
+		{
+			name: "header name is not a token",
+			modify: func(c *CORSConfig) {
+				c.AllowedHeaders = []string{"Content Type"}
+				c.ExposedHeaders = []string{"X-Total:"}
+			},
+			want: []string{
+				`AllowedHeaders[0]: must be an HTTP token of letters, digits and !#$%&'*+-.^_` + "`" + `|~ (got "Content Type")`,
+				"ExposedHeaders[0]: must be an HTTP token",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(&c)
+			err := c.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSConfigAllowsOrigin(t *testing.T) {
+	c := CORSConfig{AllowedOrigins: []string{"https://portal.tacokumo.dev", "https://*.preview.tacokumo.dev"}}
+	for origin, want := range map[string]bool{
+		"https://portal.tacokumo.dev":        true,
+		"https://pr-12.preview.tacokumo.dev": true,
+		"https://admin.tacokumo.dev":         false,
+	} {
+		if got := c.AllowsOrigin(origin); got != want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package helmcharts
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ApplyDefaults fills the zero fields of v, a pointer to a struct, from their
+// default:"..." tags. Tag values are YAML, decoded into the type of the field,
+// and set scalars or the list and mapping literals that the defaults of single
+// fields cannot express; structs are defaulted field by field instead. A field
+// whose struct type is shared by fields with other defaults, such as the
+// liveness and readiness probes, sets the leaves below it with
+// default.<path>:"..." tags, where path is the YAML path of the leaf relative
+// to the field, e.g. default.httpGet.port:"8080". Nested structs, non-nil
+// pointers, list items and map values are defaulted recursively, so the items
+// of a defaulted list also receive the defaults of their own fields.
+//
+// A zero bool cannot be told apart from an explicit false, so a bool that
+// defaults to true must be a *bool; default:"true" on a bool is an error.
+func ApplyDefaults(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("ApplyDefaults requires a non-nil pointer, got %T", v)
+	}
+	return applyDefaults(rv.Elem())
+}
+
+// applyDefaults walks v and fills the zero fields of every struct it contains
+func applyDefaults(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return applyDefaults(v.Elem())
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			field := v.Field(i)
+			if tag, ok := f.Tag.Lookup("default"); ok {
+				if err := setDefault(field, tag); err != nil {
+					return fmt.Errorf("invalid default for %s.%s: %w", t.Name(), f.Name, err)
+				}
+			}
+			for _, kv := range defaultPaths(f.Tag) {
+				if err := setDefaultPath(field, kv[0], kv[1]); err != nil {
+					return fmt.Errorf("invalid default.%s for %s.%s: %w", kv[0], t.Name(), f.Name, err)
+				}
+			}
+			if err := applyDefaults(field); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := applyDefaults(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values are not addressable, so they are defaulted on a copy
+		if k := v.Type().Elem().Kind(); k != reflect.Struct && k != reflect.Pointer {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := applyDefaults(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// setDefault decodes tag into the type of field and sets field when it is zero.
+// A struct can only be defaulted to {}, which makes a nil pointer non-nil.
+func setDefault(field reflect.Value, tag string) error {
+	if derefType(field.Type()).Kind() == reflect.Struct && tag != "{}" {
+		return fmt.Errorf("set the defaults of its fields instead of a struct literal")
+	}
+	def := reflect.New(field.Type())
+	if err := yaml.Unmarshal([]byte(tag), def.Interface()); err != nil {
+		return err
+	}
+	if field.Kind() == reflect.Bool && def.Elem().Bool() {
+		return fmt.Errorf("a bool that defaults to true must be a *bool")
+	}
+	if field.IsZero() {
+		field.Set(def.Elem())
+	}
+	return nil
+}
+
+// setDefaultPath sets the leaf at the dotted YAML path below v from tag, making
+// the nil pointers on the way non-nil
+func setDefaultPath(v reflect.Value, path, tag string) error {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("%s has no field %q", v.Type(), name)
+		}
+		field, ok := fieldByYAMLName(v, name)
+		if !ok {
+			return fmt.Errorf("%s has no field %q", v.Type(), name)
+		}
+		v = field
+	}
+	return setDefault(v, tag)
+}
+
+// fieldByYAMLName returns the exported field of the struct v whose YAML key is name
+func fieldByYAMLName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		if f := v.Type().Field(i); f.IsExported() && yamlFieldName(f) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// defaultPaths returns the paths and values of the default.<path>:"..." tags of
+// tag in their order
+func defaultPaths(tag reflect.StructTag) [][2]string {
+	var paths [][2]string
+	s := string(tag)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		key, rest, ok := strings.Cut(s, ":")
+		if !ok {
+			break
+		}
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			break
+		}
+		s = rest[len(quoted):]
+		if path, ok := strings.CutPrefix(key, "default."); ok {
+			value, _ := strconv.Unquote(quoted)
+			paths = append(paths, [2]string{path, value})
+		}
+	}
+	return paths
+}
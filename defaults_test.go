@@ -0,0 +1,139 @@
+package helmcharts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type defaultsTestPort struct {
+	Name     string `yaml:"name"`
+	Port     int    `yaml:"port"`
+	Protocol string `yaml:"protocol" default:"TCP"`
+}
+
+type defaultsTestProbe struct {
+	Path    string `yaml:"path" default:"/healthz"`
+	Port    int    `yaml:"port"`
+	Timeout *int   `yaml:"timeout" default:"5"`
+}
+
+type defaultsTestValues struct {
+	Type       string                       `yaml:"type" default:"ClusterIP"`
+	Replicas   int                          `yaml:"replicas" default:"2"`
+	Enabled    *bool                        `yaml:"enabled" default:"true"`
+	Image      Image                        `yaml:"image" default.repository:"nginx" default.tag:"latest"`
+	Ports      []defaultsTestPort           `yaml:"ports" default:"[{name: http, port: 80}]"`
+	Probe      *defaultsTestProbe           `yaml:"probe"`
+	Labels     map[string]string            `yaml:"labels" default:"{app: web}"`
+	Probes     map[string]defaultsTestProbe `yaml:"probes"`
+	NoDefault  string                       `yaml:"noDefault"`
+	unexported string                       `default:"ignored"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	var v defaultsTestValues
+	if err := ApplyDefaults(&v); err != nil {
+		t.Fatalf("ApplyDefaults() error = %v", err)
+	}
+
+	enabled := true
+	want := defaultsTestValues{
+		Type:     "ClusterIP",
+		Replicas: 2,
+		Enabled:  &enabled,
+		Image:    Image{Repository: "nginx", Tag: "latest"},
+		// Items of a defaulted list receive their own field defaults
+		Ports:  []defaultsTestPort{{Name: "http", Port: 80, Protocol: "TCP"}},
+		Labels: map[string]string{"app": "web"},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("ApplyDefaults() = %+v, want %+v", v, want)
+	}
+}
+
+func TestApplyDefaultsKeepsSetFields(t *testing.T) {
+	timeout, disabled := 30, false
+	v := defaultsTestValues{
+		Type:    "NodePort",
+		Enabled: &disabled,
+		Image:   Image{Tag: "1.27"},
+		Ports:   []defaultsTestPort{{Name: "dns", Port: 53, Protocol: "UDP"}, {Name: "metrics", Port: 9090}},
+		Probe:   &defaultsTestProbe{Port: 8080, Timeout: &timeout},
+		Probes: map[string]defaultsTestProbe{
+			"liveness": {Port: 8081},
+		},
+	}
+	if err := ApplyDefaults(&v); err != nil {
+		t.Fatalf("ApplyDefaults() error = %v", err)
+	}
+
+	if v.Type != "NodePort" {
+		t.Errorf("Type = %q, want NodePort", v.Type)
+	}
+	if *v.Enabled {
+		t.Error("Enabled = true, want the explicit false")
+	}
+	// Path defaults only fill the leaves that are still zero
+	if v.Image != (Image{Repository: "nginx", Tag: "1.27"}) {
+		t.Errorf("Image = %+v, want nginx:1.27", v.Image)
+	}
+	if len(v.Ports) != 2 || v.Ports[0].Protocol != "UDP" || v.Ports[1].Protocol != "TCP" {
+		t.Errorf("Ports = %+v, want the set ports with protocols UDP and TCP", v.Ports)
+	}
+	if v.Probe.Path != "/healthz" || v.Probe.Port != 8080 || *v.Probe.Timeout != 30 {
+		t.Errorf("Probe = %+v, want path /healthz, port 8080 and timeout 30", v.Probe)
+	}
+	if p := v.Probes["liveness"]; p.Path != "/healthz" || p.Port != 8081 || p.Timeout == nil || *p.Timeout != 5 {
+		t.Errorf("Probes[liveness] = %+v, want path /healthz, port 8081 and timeout 5", p)
+	}
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	if err := ApplyDefaults(defaultsTestValues{}); err == nil {
+		t.Error("ApplyDefaults() should reject a non-pointer")
+	}
+
+	var invalid struct {
+		Replicas int `default:"two"`
+	}
+	err := ApplyDefaults(&invalid)
+	if err == nil || !strings.Contains(err.Error(), "invalid default for .Replicas") {
+		t.Errorf("ApplyDefaults() error = %v, want invalid default for .Replicas", err)
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			name: "struct literal",
+			v: &struct {
+				Image Image `default:"{repository: nginx}"`
+			}{},
+			want: "invalid default for .Image: set the defaults of its fields instead of a struct literal",
+		},
+		{
+			name: "bool defaulting to true",
+			v: &struct {
+				Enabled bool `default:"true"`
+			}{},
+			want: "invalid default for .Enabled: a bool that defaults to true must be a *bool",
+		},
+		{
+			name: "unknown path",
+			v: &struct {
+				Image Image `default.registry:"ghcr.io"`
+			}{},
+			want: `invalid default.registry for .Image: helmcharts.Image has no field "registry"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ApplyDefaults(tt.v); err == nil || err.Error() != tt.want {
+				t.Errorf("ApplyDefaults() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package helmcharts
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeKind describes how a value differs between two sets of values
+type ChangeKind string
+
+const (
+	// Added is a map key, list item or pointer that only the new values have
+	Added ChangeKind = "added"
+	// Removed is a map key, list item or pointer that only the old values have
+	Removed ChangeKind = "removed"
+	// Changed is a value that both have but that differs
+	Changed ChangeKind = "changed"
+)
+
+// Change represents a value that differs between two sets of values
+type Change struct {
+	// Path is the YAML path of the value. Items of lists keyed by name are
+	// addressed as ports[name=http] rather than by their index.
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+
+	// Old is unset for added values and New for removed values
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// String formats the change as path: old → new
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("%s: added %s", c.Path, formatDiffValue(c.New))
+	case Removed:
+		return fmt.Sprintf("%s: removed %s", c.Path, formatDiffValue(c.Old))
+	default:
+		return fmt.Sprintf("%s: %s → %s", c.Path, formatDiffValue(c.Old), formatDiffValue(c.New))
+	}
+}
+
+// DiffValues decodes before and after into T and returns the values that
+// differ, in the order of the fields of T
+func DiffValues[T any](before, after *Document) ([]Change, error) {
+	return diffDocuments(func() any { return new(T) }, before, after)
+}
+
+// Diff merges before and after each onto the chart's values.yaml and returns
+// how the resulting values differ
+func (c *Chart) Diff(before, after *Document) ([]Change, error) {
+	base, err := ParseDocument(c.ValuesFile(), c.ValuesYAML)
+	if err != nil {
+		return nil, err
+	}
+	mergedBefore, err := MergeDocuments(base, before)
+	if err != nil {
+		return nil, err
+	}
+	mergedAfter, err := MergeDocuments(base, after)
+	if err != nil {
+		return nil, err
+	}
+	return diffDocuments(func() any { return c.NewValues() }, mergedBefore, mergedAfter)
+}
+
+func diffDocuments(newValues func() any, before, after *Document) ([]Change, error) {
+	a, b := newValues(), newValues()
+	if err := before.Decode(a); err != nil {
+		return nil, err
+	}
+	if err := after.Decode(b); err != nil {
+		return nil, err
+	}
+	return Diff(a, b), nil
+}
+
+// Diff returns how after differs from before, two values of the same type.
+// Maps are compared key by key and lists of structs with a unique name field
+// item by name, so that reordering them is not a change.
+func Diff(before, after any) []Change {
+	var changes []Change
+	diffValue(&changes, nil, reflect.ValueOf(before), reflect.ValueOf(after))
+	return changes
+}
+
+func diffValue(changes *[]Change, path yamlPathSegments, a, b reflect.Value) {
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			addChange(changes, path, a, b)
+		}
+		return
+	}
+	if a.Type().Implements(yamlMarshalerType) || reflect.PointerTo(a.Type()).Implements(yamlUnmarshalerType) {
+		// Custom YAML types are compared as a whole
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			addChange(changes, path, a, b)
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		switch {
+		case a.IsNil() && b.IsNil():
+		case a.IsNil():
+			addChange(changes, path, reflect.Value{}, b.Elem())
+		case b.IsNil():
+			addChange(changes, path, a.Elem(), reflect.Value{})
+		case a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type():
+			addChange(changes, path, a.Elem(), b.Elem())
+		default:
+			diffValue(changes, path, a.Elem(), b.Elem())
+		}
+	case reflect.Struct:
+		diffStruct(changes, path, a, b)
+	case reflect.Map:
+		diffMap(changes, path, a, b)
+	case reflect.Slice, reflect.Array:
+		if keys, ok := listKeys(a, b); ok {
+			diffKeyedList(changes, path, a, b, keys)
+		} else {
+			diffList(changes, path, a, b)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			addChange(changes, path, a, b)
+		}
+	}
+}
+
+func diffStruct(changes *[]Change, path yamlPathSegments, a, b reflect.Value) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		switch {
+		case name == "-":
+		case strings.Contains(opts, "inline"):
+			diffValue(changes, path, a.Field(i), b.Field(i))
+		case isSensitive(f):
+			n := len(*changes)
+			diffValue(changes, appendPath(path, pathSegment{name: yamlFieldName(f)}), a.Field(i), b.Field(i))
+			for j := n; j < len(*changes); j++ {
+				c := &(*changes)[j]
+				c.Old, c.New = redactAny(c.Old), redactAny(c.New)
+			}
+		default:
+			diffValue(changes, appendPath(path, pathSegment{name: yamlFieldName(f)}), a.Field(i), b.Field(i))
+		}
+	}
+}
+
+func diffMap(changes *[]Change, path yamlPathSegments, a, b reflect.Value) {
+	keys := make(map[string]reflect.Value)
+	for _, m := range []reflect.Value{a, b} {
+		for _, k := range m.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		k := keys[name]
+		diffValue(changes, appendPath(path, pathSegment{name: name, key: true}), a.MapIndex(k), b.MapIndex(k))
+	}
+}
+
+// listKeys returns the names of the items of a and b when both are lists of
+// structs whose items have unique, non-empty names
+func listKeys(a, b reflect.Value) (map[string][2]int, bool) {
+	elem := derefType(a.Type().Elem())
+	if elem.Kind() != reflect.Struct {
+		return nil, false
+	}
+	field, ok := yamlFields(elem)["name"]
+	if !ok || field.Type.Kind() != reflect.String {
+		return nil, false
+	}
+
+	keys := make(map[string][2]int)
+	for side, list := range []reflect.Value{a, b} {
+		seen := make(map[string]bool)
+		for i := 0; i < list.Len(); i++ {
+			item := reflect.Indirect(list.Index(i))
+			if !item.IsValid() {
+				return nil, false
+			}
+			name := item.FieldByIndex(field.Index).String()
+			if name == "" || seen[name] {
+				return nil, false
+			}
+			seen[name] = true
+			idx, ok := keys[name]
+			if !ok {
+				idx = [2]int{-1, -1}
+			}
+			idx[side] = i
+			keys[name] = idx
+		}
+	}
+	return keys, true
+}
+
+// diffKeyedList compares the items of a and b by name, in the order of a
+// followed by the items only b has
+func diffKeyedList(changes *[]Change, path yamlPathSegments, a, b reflect.Value, keys map[string][2]int) {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	order := func(name string) (int, int) {
+		if idx := keys[name]; idx[0] >= 0 {
+			return 0, idx[0]
+		}
+		return 1, keys[name][1]
+	}
+	slices.SortFunc(names, func(x, y string) int {
+		gx, ix := order(x)
+		gy, iy := order(y)
+		return cmp.Or(cmp.Compare(gx, gy), cmp.Compare(ix, iy))
+	})
+
+	for _, name := range names {
+		idx := keys[name]
+		var x, y reflect.Value
+		if idx[0] >= 0 {
+			x = a.Index(idx[0])
+		}
+		if idx[1] >= 0 {
+			y = b.Index(idx[1])
+		}
+		diffValue(changes, appendPath(path, pathSegment{name: name, named: true}), x, y)
+	}
+}
+
+func diffList(changes *[]Change, path yamlPathSegments, a, b reflect.Value) {
+	for i := 0; i < max(a.Len(), b.Len()); i++ {
+		var x, y reflect.Value
+		if i < a.Len() {
+			x = a.Index(i)
+		}
+		if i < b.Len() {
+			y = b.Index(i)
+		}
+		diffValue(changes, appendPath(path, pathSegment{index: i, item: true}), x, y)
+	}
+}
+
+func addChange(changes *[]Change, path yamlPathSegments, a, b reflect.Value) {
+	c := Change{Path: path.String(), Kind: Changed}
+	switch {
+	case !a.IsValid():
+		c.Kind = Added
+	case !b.IsValid():
+		c.Kind = Removed
+	}
+	// Added and removed values can be whole structs holding sensitive fields
+	if a.IsValid() {
+		c.Old = Redact(a.Interface())
+	}
+	if b.IsValid() {
+		c.New = Redact(b.Interface())
+	}
+	*changes = append(*changes, c)
+}
+
+var yamlMarshalerType = reflect.TypeFor[yaml.Marshaler]()
+
+// formatDiffValue formats v on a single line in YAML flow style
+func formatDiffValue(v any) string {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	setFlowStyle(&node)
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func setFlowStyle(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		n.Style = yaml.FlowStyle
+	}
+	for _, c := range n.Content {
+		setFlowStyle(c)
+	}
+}
@@ -0,0 +1,164 @@
+package helmcharts
+
+import (
+	"testing"
+)
+
+type diffTestPort struct {
+	Name string `yaml:"name"`
+	Port int    `yaml:"port"`
+}
+
+type diffTestValues struct {
+	Replicas    int               `yaml:"replicas"`
+	Image       Image             `yaml:"image"`
+	Ports       []diffTestPort    `yaml:"ports"`
+	Hosts       []string          `yaml:"hosts"`
+	Annotations map[string]string `yaml:"annotations"`
+	Probe       *diffTestPort     `yaml:"probe"`
+}
+
+func TestDiffValues(t *testing.T) {
+	before := mustParseDocument(t, "old.yaml", `replicas: 3
+image:
+  repository: nginx
+  tag: "1.26"
+ports:
+  - name: http
+    port: 80
+  - name: metrics
+    port: 9090
+  - name: grpc
+    port: 9000
+hosts: [a.example.com]
+annotations:
+  team: web
+  tier: frontend
+`)
+	after := mustParseDocument(t, "new.yaml", `replicas: 10
+image:
+  repository: nginx
+  tag: "1.27"
+ports:
+  - name: grpc
+    port: 9000
+  - name: https
+    port: 443
+  - name: http
+    port: 8080
+hosts: [a.example.com, b.example.com]
+annotations:
+  team: web
+  example.com/owner: platform
+probe:
+  name: health
+  port: 8081
+`)
+
+	changes, err := DiffValues[diffTestValues](before, after)
+	if err != nil {
+		t.Fatalf("DiffValues() error = %v", err)
+	}
+
+	want := []string{
+		"replicas: 3 → 10",
+		`image.tag: "1.26" → "1.27"`,
+		"ports[name=http].port: 80 → 8080",
+		"ports[name=metrics]: removed {name: metrics, port: 9090}",
+		"ports[name=https]: added {name: https, port: 443}",
+		"hosts[1]: added b.example.com",
+		`annotations["example.com/owner"]: added platform`,
+		"annotations.tier: removed frontend",
+		"probe: added {name: health, port: 8081}",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		if got := changes[i].String(); got != w {
+			t.Errorf("change %d = %q, want %q", i, got, w)
+		}
+	}
+	if c := changes[0]; c.Kind != Changed || c.Old != 3 || c.New != 10 {
+		t.Errorf("change 0 = %+v", c)
+	}
+	if c := changes[3]; c.Kind != Removed || c.New != nil {
+		t.Errorf("change 3 = %+v", c)
+	}
+}
+
+func TestDiffUnkeyedLists(t *testing.T) {
+	// Without unique names, list items are compared by position
+	before := diffTestValues{Ports: []diffTestPort{{Name: "http", Port: 80}, {Name: "http", Port: 81}}}
+	after := diffTestValues{Ports: []diffTestPort{{Name: "http", Port: 80}}}
+
+	changes := Diff(&before, &after)
+	if len(changes) != 1 || changes[0].String() != "ports[1]: removed {name: http, port: 81}" {
+		t.Errorf("Diff() = %v", changes)
+	}
+}
+
+type diffTestDatabase struct {
+	Name     string      `yaml:"name"`
+	Password SecretValue `yaml:"password" sensitive:"true"`
+}
+
+type diffTestSecretValues struct {
+	Databases []diffTestDatabase          `yaml:"databases"`
+	Primary   *diffTestDatabase           `yaml:"primary"`
+	Replicas  map[string]diffTestDatabase `yaml:"replicas"`
+}
+
+func TestDiffRedactsAddedAndRemovedValues(t *testing.T) {
+	db := diffTestDatabase{Name: "app", Password: SecretValue{Value: "hunter2"}}
+	before := diffTestSecretValues{Databases: []diffTestDatabase{{Name: "old", Password: SecretValue{Value: "s3cret"}}}}
+	after := diffTestSecretValues{Databases: []diffTestDatabase{db}, Primary: &db, Replicas: map[string]diffTestDatabase{"eu": db}}
+
+	changes := Diff(before, after)
+	want := []string{
+		"databases[name=old]: removed {name: old, password: '***'}",
+		"databases[name=app]: added {name: app, password: '***'}",
+		"primary: added {name: app, password: '***'}",
+		"replicas.eu: added {name: app, password: '***'}",
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		if got := changes[i].String(); got != w {
+			t.Errorf("change %d = %q, want %q", i, got, w)
+		}
+	}
+	if db.Password.Value != "hunter2" {
+		t.Errorf("Diff() modified the values: %+v", db)
+	}
+}
+
+func TestDiffIdenticalValues(t *testing.T) {
+	before := diffTestValues{Ports: []diffTestPort{{Name: "a", Port: 1}, {Name: "b", Port: 2}}, Annotations: map[string]string{}}
+	after := diffTestValues{Ports: []diffTestPort{{Name: "b", Port: 2}, {Name: "a", Port: 1}}}
+
+	// Reordering named items and nil versus empty maps are not changes
+	if changes := Diff(before, after); len(changes) != 0 {
+		t.Errorf("Diff() = %v, want no changes", changes)
+	}
+}
+
+func TestChartDiff(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(newRegistryTestChart("name: web\nversion: 1.2.3\n")); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := r.Lookup("web")
+
+	// Setting a value to its chart default is not a change
+	before := mustParseDocument(t, "old.yaml", "service:\n  type: ClusterIP\n")
+	after := mustParseDocument(t, "new.yaml", "service:\n  port: 8080\n")
+	changes, err := c.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].String() != "service.port: 80 → 8080" {
+		t.Errorf("Diff() = %v", changes)
+	}
+}
@@ -0,0 +1,156 @@
+package helmcharts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError represents a validation failure of a single field
+type FieldError struct {
+	// Field is the Go field path relative to the validated value (e.g. HPA.MaxReplicas)
+	Field string
+
+	// Tag is the validation rule that failed (e.g. required_if)
+	Tag   string
+	Param string
+	Value any
+	Kind  reflect.Kind
+
+	// Message overrides the message derived from Tag when set
+	Message string
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = tagMessage(e.Tag, e.Param, e.Kind, e.Value, nil)
+	}
+	if e.Field == "" {
+		return msg
+	}
+	return e.Field + ": " + msg
+}
+
+// Errors aggregates every validation failure of a value and its nested sections.
+// Failures keep the order in which they were collected and each field is
+// reported at most once per tag, so the result is stable enough to assert in
+// tests.
+type Errors []error
+
+// Error implements the error interface
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the collected errors
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Err returns the collected errors, or nil when there are none
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Struct validates s against its struct tags and collects the failures. The
+// values of fields tagged sensitive:"true" are redacted from the failures.
+func (e *Errors) Struct(s any) {
+	err := ValidateStruct(s)
+	ves, ok := err.(validator.ValidationErrors)
+	if !ok {
+		e.Nested("", err)
+		return
+	}
+	root := reflect.TypeOf(s)
+	for _, v := range ves {
+		fe := newFieldError(v)
+		if sensitivePath(root, parseNamespace(fe.Field)) {
+			fe.Value = redactAny(fe.Value)
+		}
+		e.add(fe)
+	}
+}
+
+// Add collects the failure of a hand-written check on field
+func (e *Errors) Add(field, tag, message string) {
+	e.add(&FieldError{Field: field, Tag: tag, Message: message})
+}
+
+// Nested collects err, as returned by the Validate method of the section stored
+// in field, rebasing the failures onto field
+func (e *Errors) Nested(field string, err error) {
+	if err == nil {
+		return
+	}
+	switch err := err.(type) {
+	case validator.ValidationErrors:
+		for _, v := range err {
+			e.add(rebase(field, newFieldError(v)))
+		}
+	case Errors:
+		for _, n := range err {
+			e.Nested(field, n)
+		}
+	case *FieldError:
+		e.add(rebase(field, err))
+	default:
+		if field == "" {
+			*e = append(*e, err)
+			return
+		}
+		e.add(&FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+// add appends fe unless its field has already failed with the same tag
+func (e *Errors) add(fe *FieldError) {
+	for _, err := range *e {
+		if existing, ok := err.(*FieldError); ok && existing.Field == fe.Field && existing.Tag == fe.Tag {
+			return
+		}
+	}
+	*e = append(*e, fe)
+}
+
+// newFieldError converts a validator failure, dropping the validated struct's name
+func newFieldError(fe validator.FieldError) *FieldError {
+	_, field, _ := strings.Cut(fe.StructNamespace(), ".")
+	return &FieldError{
+		Field: field,
+		Tag:   fe.Tag(),
+		Param: fe.Param(),
+		Value: fe.Value(),
+		Kind:  fe.Kind(),
+	}
+}
+
+// rebase returns a copy of fe with its field prefixed by field
+func rebase(field string, fe *FieldError) *FieldError {
+	out := *fe
+	out.Field = joinField(field, fe.Field)
+	return &out
+}
+
+func joinField(prefix, field string) string {
+	switch {
+	case prefix == "":
+		return field
+	case field == "":
+		return prefix
+	case strings.HasPrefix(field, "["):
+		return prefix + field
+	default:
+		return fmt.Sprintf("%s.%s", prefix, field)
+	}
+}
@@ -0,0 +1,70 @@
+package helmcharts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorsCollectsAndRebases(t *testing.T) {
+	var nested Errors
+	nested.Struct(&Service{Type: "Bogus", Port: 0})
+	nested.Add("Port", "min", "must be a valid port")
+
+	var errs Errors
+	errs.Add("Enabled", "required", "must be enabled")
+	errs.Nested("Service", nested.Err())
+	errs.Nested("Ingress", ValidateStruct(&Ingress{Enabled: true}))
+	errs.Nested("TLS", errors.New("certificate missing"))
+
+	want := []string{
+		"Enabled",
+		"Service.Type",
+		"Service.Port",
+		"Ingress.ClassName",
+		"Ingress.Hosts",
+		"TLS",
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(errs), len(want), errs)
+	}
+	for i, field := range want {
+		fe, ok := errs[i].(*FieldError)
+		if !ok {
+			t.Fatalf("error %d is %T, want *FieldError", i, errs[i])
+		}
+		if fe.Field != field {
+			t.Errorf("error %d field = %q, want %q", i, fe.Field, field)
+		}
+	}
+
+	// The hand-written Service.Port check is dropped because the struct tag already failed
+	if fe := errs[2].(*FieldError); fe.Tag != "min" || fe.Message != "" {
+		t.Errorf("Service.Port error = %+v, want the struct tag failure", fe)
+	}
+}
+
+func TestErrorsKeepsDistinctTagsOfAField(t *testing.T) {
+	var errs Errors
+	errs.Add("CertFile", "tls_expiry", "certificate expired")
+	errs.Add("CertFile", "tls_hostname", "certificate does not cover app.example.com")
+	errs.Add("CertFile", "tls_expiry", "certificate is not valid yet")
+
+	want := "CertFile: certificate expired\nCertFile: certificate does not cover app.example.com"
+	if got := errs.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorsErr(t *testing.T) {
+	var errs Errors
+	if errs.Err() != nil {
+		t.Error("empty Errors should return a nil error")
+	}
+
+	errs.Add("Port", "min", "must be at least 1")
+	errs.Add("Path", "required", "is required")
+	if got, want := errs.Err().Error(), "Port: must be at least 1\nPath: is required"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -1,17 +1,15 @@
 package helmcharts
 
-import (
-	"github.com/go-playground/validator/v10"
-)
+import "fmt"
 
 // PostgreSQLConfig represents PostgreSQL database configuration
 type PostgreSQLConfig struct {
-	Host     string `yaml:"host" validate:"required,fqdn|ip"`
-	Port     int    `yaml:"port" validate:"required,min=1,max=65535"`
-	Database string `yaml:"database" validate:"required"`
-	Username string `yaml:"username" validate:"required"`
-	Password string `yaml:"password" validate:"required"`
-	SSLMode  string `yaml:"sslMode,omitempty" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"`
+	Host     string      `yaml:"host" validate:"required,fqdn|ip"`
+	Port     int         `yaml:"port" validate:"required,min=1,max=65535"`
+	Database string      `yaml:"database" validate:"required"`
+	Username string      `yaml:"username" validate:"required"`
+	Password SecretValue `yaml:"password" sensitive:"true"`
+	SSLMode  string      `yaml:"sslMode,omitempty" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"`
 
 	// Connection pool settings
 	MaxOpenConns    int    `yaml:"maxOpenConns,omitempty" validate:"omitempty,min=1"`
@@ -22,10 +20,20 @@ type PostgreSQLConfig struct {
 
 // RedisConfig represents Redis configuration
 type RedisConfig struct {
-	Host     string `yaml:"host" validate:"required,fqdn|ip"`
-	Port     int    `yaml:"port" validate:"required,min=1,max=65535"`
-	Password string `yaml:"password,omitempty"`
-	DB       int    `yaml:"db" validate:"min=0,max=15"`
+	// Mode is the topology: standalone (the default) connects to Host and
+	// Port, sentinel asks SentinelAddresses for the address of SentinelMaster
+	// and cluster discovers the nodes from ClusterNodes
+	Mode              string   `yaml:"mode,omitempty" validate:"omitempty,oneof=standalone sentinel cluster"`
+	Host              string   `yaml:"host,omitempty" validate:"omitempty,fqdn|ip"`
+	Port              int      `yaml:"port,omitempty" validate:"omitempty,min=1,max=65535"`
+	SentinelMaster    string   `yaml:"sentinelMaster,omitempty"`
+	SentinelAddresses []string `yaml:"sentinelAddresses,omitempty" validate:"omitempty,dive,host_port"`
+	ClusterNodes      []string `yaml:"clusterNodes,omitempty" validate:"omitempty,dive,host_port"`
+
+	Username string      `yaml:"username,omitempty"`
+	Password SecretValue `yaml:"password,omitempty" sensitive:"true"`
+	DB       int         `yaml:"db" validate:"min=0,max=15"`
+	TLS      bool        `yaml:"tls,omitempty"`
 
 	// Connection settings
 	MaxRetries      int    `yaml:"maxRetries,omitempty" validate:"omitempty,min=0"`
@@ -48,35 +56,38 @@ type RedisConfig struct {
 type AuthConfig struct {
 	Enabled bool `yaml:"enabled"`
 
-	// OAuth settings
-	OAuthClientID     string   `yaml:"oauthClientId" validate:"required_if=Enabled true"`
-	OAuthClientSecret string   `yaml:"oauthClientSecret" validate:"required_if=Enabled true"`
-	OAuthAuthURL      string   `yaml:"oauthAuthUrl" validate:"required_if=Enabled true,url"`
-	OAuthTokenURL     string   `yaml:"oauthTokenUrl" validate:"required_if=Enabled true,url"`
-	OAuthCallbackURL  string   `yaml:"oauthCallbackUrl" validate:"required_if=Enabled true,url"`
-	OAuthScopes       []string `yaml:"oauthScopes,omitempty"`
+	// OAuth settings. With OIDCIssuer set, the endpoints can be left out and
+	// are read from the issuer's discovery document (see AuthConfig.Discover).
+	OIDCIssuer        string      `yaml:"oidcIssuer,omitempty" validate:"omitempty,url"`
+	OAuthClientID     string      `yaml:"oauthClientId" validate:"required_if=Enabled true"`
+	OAuthClientSecret SecretValue `yaml:"oauthClientSecret" sensitive:"true"`
+	OAuthAuthURL      string      `yaml:"oauthAuthUrl" validate:"omitempty,url"`
+	OAuthTokenURL     string      `yaml:"oauthTokenUrl" validate:"omitempty,url"`
+	OAuthJWKSURL      string      `yaml:"oauthJwksUrl,omitempty" validate:"omitempty,url"`
+	OAuthCallbackURL  string      `yaml:"oauthCallbackUrl" validate:"required_if=Enabled true,omitempty,url"`
+	OAuthScopes       []string    `yaml:"oauthScopes,omitempty"`
 
 	// Session settings
-	SessionSecret   string `yaml:"sessionSecret" validate:"required_if=Enabled true,min=32"`
-	SessionName     string `yaml:"sessionName" validate:"required_if=Enabled true"`
-	SessionTTL      string `yaml:"sessionTtl" validate:"required_if=Enabled true,duration"`
-	SessionSecure   bool   `yaml:"sessionSecure"`
-	SessionSameSite string `yaml:"sessionSameSite" validate:"omitempty,oneof=Strict Lax None"`
-	SessionDomain   string `yaml:"sessionDomain,omitempty" validate:"omitempty,fqdn"`
+	SessionSecret   SecretValue `yaml:"sessionSecret" sensitive:"true"`
+	SessionName     string      `yaml:"sessionName" validate:"required_if=Enabled true"`
+	SessionTTL      string      `yaml:"sessionTtl" validate:"required_if=Enabled true,duration"`
+	SessionSecure   bool        `yaml:"sessionSecure"`
+	SessionSameSite string      `yaml:"sessionSameSite" validate:"omitempty,oneof=Strict Lax None"`
+	SessionDomain   string      `yaml:"sessionDomain,omitempty" validate:"omitempty,fqdn"`
 
 	// JWT settings
-	JWTSecret            string `yaml:"jwtSecret,omitempty" validate:"omitempty,min=32"`
-	JWTExpiration        string `yaml:"jwtExpiration,omitempty" validate:"omitempty,duration"`
-	JWTRefreshExpiration string `yaml:"jwtRefreshExpiration,omitempty" validate:"omitempty,duration"`
+	JWTSecret            SecretValue `yaml:"jwtSecret,omitempty" sensitive:"true"`
+	JWTExpiration        string      `yaml:"jwtExpiration,omitempty" validate:"omitempty,duration"`
+	JWTRefreshExpiration string      `yaml:"jwtRefreshExpiration,omitempty" validate:"omitempty,duration"`
 }
 
 // CORSConfig represents CORS policy configuration
 type CORSConfig struct {
 	Enabled          bool     `yaml:"enabled"`
-	AllowedOrigins   []string `yaml:"allowedOrigins" validate:"required_if=Enabled true,dive,url"`
+	AllowedOrigins   []string `yaml:"allowedOrigins" validate:"required_if=Enabled true,dive,cors_origin"`
 	AllowedMethods   []string `yaml:"allowedMethods" validate:"required_if=Enabled true,dive,oneof=GET POST PUT DELETE PATCH HEAD OPTIONS"`
-	AllowedHeaders   []string `yaml:"allowedHeaders,omitempty"`
-	ExposedHeaders   []string `yaml:"exposedHeaders,omitempty"`
+	AllowedHeaders   []string `yaml:"allowedHeaders,omitempty" validate:"omitempty,dive,http_token"`
+	ExposedHeaders   []string `yaml:"exposedHeaders,omitempty" validate:"omitempty,dive,http_token"`
 	AllowCredentials bool     `yaml:"allowCredentials"`
 	MaxAge           int      `yaml:"maxAge,omitempty" validate:"omitempty,min=0"`
 }
@@ -97,14 +108,18 @@ type OpenTelemetryConfig struct {
 	ServiceName    string `yaml:"serviceName" validate:"required_if=Enabled true"`
 	ServiceVersion string `yaml:"serviceVersion,omitempty"`
 
-	// Tracing
-	TracingEnabled  bool    `yaml:"tracingEnabled"`
-	TracingEndpoint string  `yaml:"tracingEndpoint" validate:"required_if=TracingEnabled true,url"`
-	TracingSampling float64 `yaml:"tracingSampling" validate:"min=0,max=1"`
+	// Tracing. The sampler defaults to parentbased_traceidratio, with
+	// TracingSampling as the ratio, which is 1 when it is not set.
+	TracingEnabled  bool     `yaml:"tracingEnabled"`
+	TracingEndpoint string   `yaml:"tracingEndpoint" validate:"required_if=TracingEnabled true,omitempty,url"`
+	TracingProtocol string   `yaml:"tracingProtocol,omitempty" validate:"omitempty,oneof=grpc http/protobuf http/json"`
+	TracingSampler  string   `yaml:"tracingSampler,omitempty" validate:"omitempty,oneof=always_on always_off traceidratio parentbased_always_on parentbased_always_off parentbased_traceidratio"`
+	TracingSampling *float64 `yaml:"tracingSampling,omitempty" validate:"omitempty,min=0,max=1"`
 
 	// Metrics
 	MetricsEnabled  bool   `yaml:"metricsEnabled"`
-	MetricsEndpoint string `yaml:"metricsEndpoint" validate:"required_if=MetricsEnabled true,url"`
+	MetricsEndpoint string `yaml:"metricsEndpoint" validate:"required_if=MetricsEnabled true,omitempty,url"`
+	MetricsProtocol string `yaml:"metricsProtocol,omitempty" validate:"omitempty,oneof=grpc http/protobuf http/json"`
 	MetricsInterval string `yaml:"metricsInterval" validate:"omitempty,duration"`
 
 	// Logging
@@ -113,10 +128,10 @@ type OpenTelemetryConfig struct {
 	LogFormat      string `yaml:"logFormat" validate:"omitempty,oneof=json text"`
 
 	// Resource attributes
-	ResourceAttributes map[string]string `yaml:"resourceAttributes,omitempty"`
+	ResourceAttributes map[string]string `yaml:"resourceAttributes,omitempty" validate:"omitempty,dive,keys,http_token,endkeys"`
 
 	// Headers for authentication
-	Headers map[string]string `yaml:"headers,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" validate:"omitempty,dive,keys,http_token,endkeys" sensitive:"true"`
 }
 
 // ExternalServiceConfig represents configuration for external service dependencies
@@ -140,31 +155,110 @@ type ExternalServiceConfig struct {
 	OpenTelemetry OpenTelemetryConfig `yaml:"openTelemetry"`
 }
 
+// minSecretLength is the minimum length of literal signing secrets
+const minSecretLength = 32
+
 // Validate validates the external service configurations
 func (p *PostgreSQLConfig) Validate() error {
-	return validator.New().Struct(p)
+	var errs Errors
+	errs.Struct(p)
+	errs.Secret("Password", p.Password, true)
+	return errs.Err()
 }
 
 func (r *RedisConfig) Validate() error {
-	return validator.New().Struct(r)
+	var errs Errors
+	errs.Struct(r)
+	errs.Secret("Password", r.Password, false)
+
+	mode := r.mode()
+	required := func(field string, set bool) {
+		if !set {
+			errs.Add(field, "required", fmt.Sprintf("is required in %s mode", mode))
+		}
+	}
+	unused := func(field string, set bool) {
+		if set {
+			errs.Add(field, "redis_mode", fmt.Sprintf("is not used in %s mode", mode))
+		}
+	}
+	switch mode {
+	case RedisModeStandalone:
+		required("Host", r.Host != "")
+		required("Port", r.Port != 0)
+		unused("SentinelMaster", r.SentinelMaster != "")
+		unused("SentinelAddresses", len(r.SentinelAddresses) > 0)
+		unused("ClusterNodes", len(r.ClusterNodes) > 0)
+	case RedisModeSentinel:
+		required("SentinelMaster", r.SentinelMaster != "")
+		required("SentinelAddresses", len(r.SentinelAddresses) > 0)
+		unused("Host", r.Host != "")
+		unused("Port", r.Port != 0)
+		unused("ClusterNodes", len(r.ClusterNodes) > 0)
+	case RedisModeCluster:
+		required("ClusterNodes", len(r.ClusterNodes) > 0)
+		unused("Host", r.Host != "")
+		unused("Port", r.Port != 0)
+		unused("SentinelMaster", r.SentinelMaster != "")
+		unused("SentinelAddresses", len(r.SentinelAddresses) > 0)
+		if r.DB != 0 {
+			errs.Add("DB", "redis_mode", "must be 0 in cluster mode, which only has database 0")
+		}
+	}
+	return errs.Err()
 }
 
 func (a *AuthConfig) Validate() error {
-	return validator.New().Struct(a)
+	var errs Errors
+	errs.Struct(a)
+	if a.Enabled && a.OIDCIssuer == "" {
+		for _, f := range []struct{ field, value string }{
+			{"OAuthAuthURL", a.OAuthAuthURL},
+			{"OAuthTokenURL", a.OAuthTokenURL},
+		} {
+			if f.value == "" {
+				errs.Add(f.field, "required", "is required when enabled is true, unless oidcIssuer is set")
+			}
+		}
+	}
+	errs.Secret("OAuthClientSecret", a.OAuthClientSecret, a.Enabled)
+	errs.Secret("SessionSecret", a.SessionSecret, a.Enabled)
+	errs.SecretMinLength("SessionSecret", a.SessionSecret, minSecretLength)
+	errs.Secret("JWTSecret", a.JWTSecret, false)
+	errs.SecretMinLength("JWTSecret", a.JWTSecret, minSecretLength)
+	return errs.Err()
 }
 
 func (c *CORSConfig) Validate() error {
-	return validator.New().Struct(c)
+	var errs Errors
+	errs.Struct(c)
+	c.validateCredentials(&errs)
+	return errs.Err()
 }
 
 func (t *TLSConfig) Validate() error {
-	return validator.New().Struct(t)
+	var errs Errors
+	errs.Struct(t)
+	// The versions are oneof 1.0 to 1.3, which sort as strings
+	if t.MinVersion != "" && t.MaxVersion != "" && t.MinVersion > t.MaxVersion {
+		errs.Add("MinVersion", "tls_version", fmt.Sprintf("must not be greater than maxVersion %s (got %q)", t.MaxVersion, t.MinVersion))
+	}
+	return errs.Err()
 }
 
 func (o *OpenTelemetryConfig) Validate() error {
-	return validator.New().Struct(o)
+	var errs Errors
+	errs.Struct(o)
+	return errs.Err()
 }
 
 func (e *ExternalServiceConfig) Validate() error {
-	return validator.New().Struct(e)
+	var errs Errors
+	errs.Struct(e)
+	errs.Nested("Database", e.Database.Validate())
+	errs.Nested("Redis", e.Redis.Validate())
+	errs.Nested("Auth", e.Auth.Validate())
+	errs.Nested("CORS", e.CORS.Validate())
+	errs.Nested("TLS", e.TLS.Validate())
+	return errs.Err()
 }
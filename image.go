@@ -0,0 +1,237 @@
+package helmcharts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultImageRegistry is the registry of image references that do not name one
+const DefaultImageRegistry = "docker.io"
+
+// maxImageNameLength bounds the registry and repository of a reference, as in Docker
+const maxImageNameLength = 255
+
+var (
+	// registryPattern matches a registry host with an optional port, e.g. ghcr.io or localhost:5000
+	registryPattern = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(:[0-9]+)?$`)
+	// repositoryComponentPattern matches one slash separated component of a repository
+	repositoryComponentPattern = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	// imageTagPattern matches a tag such as 1.27 or v2.0.0-rc.1
+	imageTagPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+	// imageDigestPattern matches a digest such as sha256:0123…
+	imageDigestPattern = regexp.MustCompile(`^[a-z0-9]+([+._-][a-z0-9]+)*:[a-f0-9]{32,}$`)
+)
+
+// digestLengths is the length of the hex encoded digests of known algorithms
+var digestLengths = map[string]int{
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// ImageReferenceError describes why an image reference is malformed
+type ImageReferenceError struct {
+	Reference string
+	Reason    string
+}
+
+// Error implements the error interface
+func (e *ImageReferenceError) Error() string {
+	return fmt.Sprintf("invalid image reference %q: %s", e.Reference, e.Reason)
+}
+
+// ImageReference represents a parsed container image reference such as
+// ghcr.io/tacokumo/portal:1.2.0 or nginx@sha256:…
+type ImageReference struct {
+	// Registry is the registry host, DefaultImageRegistry when the reference has none
+	Registry string
+
+	// Repository is the path of the image within the registry. Official images
+	// of the default registry are normalised to library/<name>.
+	Repository string
+
+	// Tag and Digest are empty when the reference does not have them
+	Tag    string
+	Digest string
+}
+
+// ParseImageReference parses ref following the grammar used by Docker and
+// Kubernetes: [registry[:port]/]repository[:tag][@digest]. The first component
+// is the registry when it contains a dot or a port or is localhost.
+func ParseImageReference(ref string) (ImageReference, error) {
+	invalid := func(format string, args ...any) (ImageReference, error) {
+		return ImageReference{}, &ImageReferenceError{Reference: ref, Reason: fmt.Sprintf(format, args...)}
+	}
+	if ref == "" {
+		return invalid("reference is empty")
+	}
+	if strings.TrimSpace(ref) != ref {
+		return invalid("reference must not contain spaces")
+	}
+
+	var r ImageReference
+	name := ref
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, r.Digest = name[:i], name[i+1:]
+		if err := validateDigest(r.Digest); err != nil {
+			return invalid("%v", err)
+		}
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, r.Tag = name[:i], name[i+1:]
+		if !imageTagPattern.MatchString(r.Tag) {
+			if r.Tag == "" {
+				return invalid("tag is empty")
+			}
+			return invalid("tag %q must be at most 128 letters, digits, underscores, periods and dashes, not starting with a period or dash", r.Tag)
+		}
+	}
+	if name == "" {
+		return invalid("repository is empty")
+	}
+	if len(name) > maxImageNameLength {
+		return invalid("name must be at most %d characters long", maxImageNameLength)
+	}
+
+	r.Registry, r.Repository = splitRegistry(name)
+	if !registryPattern.MatchString(r.Registry) {
+		return invalid("registry %q must be a host name with an optional port", r.Registry)
+	}
+	for _, component := range strings.Split(r.Repository, "/") {
+		if repositoryComponentPattern.MatchString(component) {
+			continue
+		}
+		if repositoryComponentPattern.MatchString(strings.ToLower(component)) {
+			return invalid("repository %q must be lower case", r.Repository)
+		}
+		return invalid("repository %q must consist of lower case letters, digits and separators (., _, __, -) between slashes", r.Repository)
+	}
+
+	if r.Registry == "index.docker.io" {
+		r.Registry = DefaultImageRegistry
+	}
+	if r.Registry == DefaultImageRegistry && !strings.Contains(r.Repository, "/") {
+		r.Repository = "library/" + r.Repository
+	}
+	return r, nil
+}
+
+// splitRegistry splits name into its registry, the default one when name has
+// none, and its repository
+func splitRegistry(name string) (string, string) {
+	first, rest, found := strings.Cut(name, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		return DefaultImageRegistry, name
+	}
+	return first, rest
+}
+
+// validateDigest checks the algorithm:hex form of a digest and the length of known algorithms
+func validateDigest(digest string) error {
+	if !imageDigestPattern.MatchString(digest) {
+		return fmt.Errorf("digest %q must be an algorithm and a lower case hex string such as sha256:<64 hex digits>", digest)
+	}
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	if n, ok := digestLengths[algorithm]; ok && len(hex) != n {
+		return fmt.Errorf("%s digest must have %d hex digits (got %d)", algorithm, n, len(hex))
+	}
+	return nil
+}
+
+// Name returns the registry and repository of the reference
+func (r ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the normalised reference, e.g. docker.io/library/nginx:1.27
+func (r ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Familiar returns the reference the way it is usually written, without the
+// default registry and the library/ prefix of official images
+func (r ImageReference) Familiar() string {
+	s := r.String()
+	if r.Registry != DefaultImageRegistry {
+		return s
+	}
+	s = strings.TrimPrefix(s, DefaultImageRegistry+"/")
+	if name, ok := strings.CutPrefix(r.Repository, "library/"); ok && !strings.Contains(name, "/") {
+		return strings.TrimPrefix(s, "library/")
+	}
+	return s
+}
+
+// Image converts the reference to the repository and tag of an Image. A digest
+// is kept after the tag (1.27@sha256:…), which the charts render as
+// repository:tag@digest. References without a tag cannot be converted.
+func (r ImageReference) Image() (Image, error) {
+	if r.Tag == "" {
+		return Image{}, fmt.Errorf("image %s has no tag", r.Familiar())
+	}
+	tag := r.Tag
+	if r.Digest != "" {
+		tag += "@" + r.Digest
+	}
+	return Image{Repository: r.Name(), Tag: tag}, nil
+}
+
+// ImagePolicy restricts the image references a deployment may use
+type ImagePolicy struct {
+	// ForbidLatest rejects the latest tag and references with neither a tag
+	// nor a digest, which pull latest
+	ForbidLatest bool
+
+	// RequireDigest rejects references that are not pinned to a digest
+	RequireDigest bool
+}
+
+// productionStages are the stage names that ImagePolicyForStage treats as production
+var productionStages = []string{"prod", "production"}
+
+// ImagePolicyForStage returns the image policy of a deployment stage: every
+// stage forbids latest and production stages (prod, production) also require
+// digests, so that what was tested is exactly what runs
+func ImagePolicyForStage(stage string) ImagePolicy {
+	policy := ImagePolicy{ForbidLatest: true}
+	for _, s := range productionStages {
+		if strings.EqualFold(stage, s) {
+			policy.RequireDigest = true
+		}
+	}
+	return policy
+}
+
+// ImagePolicyError describes why an image reference breaks an ImagePolicy
+type ImagePolicyError struct {
+	Reference ImageReference
+	Reason    string
+}
+
+// Error implements the error interface
+func (e *ImagePolicyError) Error() string {
+	return fmt.Sprintf("image %s %s", e.Reference.Familiar(), e.Reason)
+}
+
+// Check returns an *ImagePolicyError when ref breaks the policy
+func (p ImagePolicy) Check(ref ImageReference) error {
+	var reason string
+	switch {
+	case p.ForbidLatest && ref.Tag == "latest":
+		reason = "must not use the latest tag; pin a version"
+	case p.ForbidLatest && ref.Tag == "" && ref.Digest == "":
+		reason = "has no tag, so latest is pulled; pin a version"
+	case p.RequireDigest && ref.Digest == "":
+		reason = "must be pinned to a digest such as @sha256:…"
+	default:
+		return nil
+	}
+	return &ImagePolicyError{Reference: ref, Reason: reason}
+}
@@ -0,0 +1,223 @@
+package helmcharts
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		ref      string
+		want     ImageReference
+		familiar string
+	}{
+		{
+			ref:      "nginx",
+			want:     ImageReference{Registry: "docker.io", Repository: "library/nginx"},
+			familiar: "nginx",
+		},
+		{
+			ref:      "nginx:1.27",
+			want:     ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.27"},
+			familiar: "nginx:1.27",
+		},
+		{
+			ref:      "bitnami/redis:7.2",
+			want:     ImageReference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"},
+			familiar: "bitnami/redis:7.2",
+		},
+		{
+			ref:      "index.docker.io/library/nginx",
+			want:     ImageReference{Registry: "docker.io", Repository: "library/nginx"},
+			familiar: "nginx",
+		},
+		{
+			ref:      "ghcr.io/tacokumo/portal:v1.2.0-rc.1",
+			want:     ImageReference{Registry: "ghcr.io", Repository: "tacokumo/portal", Tag: "v1.2.0-rc.1"},
+			familiar: "ghcr.io/tacokumo/portal:v1.2.0-rc.1",
+		},
+		{
+			ref:      "localhost:5000/app",
+			want:     ImageReference{Registry: "localhost:5000", Repository: "app"},
+			familiar: "localhost:5000/app",
+		},
+		{
+			ref:      "localhost/my_app__v2/web-server",
+			want:     ImageReference{Registry: "localhost", Repository: "my_app__v2/web-server"},
+			familiar: "localhost/my_app__v2/web-server",
+		},
+		{
+			ref:      "nginx@" + testDigest,
+			want:     ImageReference{Registry: "docker.io", Repository: "library/nginx", Digest: testDigest},
+			familiar: "nginx@" + testDigest,
+		},
+		{
+			ref:      "registry.example.com:443/team/app:1.0@" + testDigest,
+			want:     ImageReference{Registry: "registry.example.com:443", Repository: "team/app", Tag: "1.0", Digest: testDigest},
+			familiar: "registry.example.com:443/team/app:1.0@" + testDigest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ParseImageReference(tt.ref)
+			if err != nil {
+				t.Fatalf("ParseImageReference() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseImageReference() = %+v, want %+v", got, tt.want)
+			}
+			if f := got.Familiar(); f != tt.familiar {
+				t.Errorf("Familiar() = %q, want %q", f, tt.familiar)
+			}
+
+			// The normalised form parses to the same reference
+			again, err := ParseImageReference(got.String())
+			if err != nil || again != got {
+				t.Errorf("ParseImageReference(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestParseImageReferenceErrors(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "", want: "reference is empty"},
+		{ref: " nginx", want: "must not contain spaces"},
+		{ref: "nginx::latest", want: `repository "nginx:" must consist of lower case letters`},
+		{ref: "nginx:", want: "tag is empty"},
+		{ref: "nginx:-rc", want: `tag "-rc" must be at most 128 letters`},
+		{ref: "UPPER/case", want: `repository "UPPER/case" must be lower case`},
+		{ref: "ghcr.io/Tacokumo/portal", want: `repository "Tacokumo/portal" must be lower case`},
+		{ref: "ghcr.io//portal", want: `repository "/portal" must consist of`},
+		{ref: "app-/web", want: `repository "app-/web" must consist of`},
+		{ref: "-registry.io/app", want: `registry "-registry.io" must be a host name`},
+		{ref: ":1.0", want: "repository is empty"},
+		{ref: "nginx@sha256:abc", want: `digest "sha256:abc" must be an algorithm and a lower case hex string`},
+		{ref: "nginx@sha256:" + strings.Repeat("a", 40), want: "sha256 digest must have 64 hex digits (got 40)"},
+		{ref: "nginx@" + testDigest + "@" + testDigest, want: "must be an algorithm"},
+		{ref: strings.Repeat("a", 256), want: "name must be at most 255 characters long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			_, err := ParseImageReference(tt.ref)
+			var ire *ImageReferenceError
+			if !errors.As(err, &ire) {
+				t.Fatalf("ParseImageReference(%q) error = %v, want an *ImageReferenceError", tt.ref, err)
+			}
+			if !strings.Contains(ire.Reason, tt.want) {
+				t.Errorf("reason = %q, want it to contain %q", ire.Reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageReferenceImage(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    Image
+		wantErr bool
+	}{
+		{ref: "nginx:1.27", want: Image{Repository: "docker.io/library/nginx", Tag: "1.27"}},
+		{ref: "ghcr.io/tacokumo/portal:1.0@" + testDigest, want: Image{Repository: "ghcr.io/tacokumo/portal", Tag: "1.0@" + testDigest}},
+		{ref: "nginx@" + testDigest, wantErr: true},
+		{ref: "nginx", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			ref, err := ParseImageReference(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ref.Image()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Image() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Image() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImagePolicyCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ImagePolicy
+		ref    string
+		want   string
+	}{
+		{name: "no policy", policy: ImagePolicy{}, ref: "nginx"},
+		{name: "latest", policy: ImagePolicy{ForbidLatest: true}, ref: "nginx:latest", want: "image nginx:latest must not use the latest tag; pin a version"},
+		{name: "untagged", policy: ImagePolicy{ForbidLatest: true}, ref: "ghcr.io/tacokumo/portal", want: "image ghcr.io/tacokumo/portal has no tag, so latest is pulled; pin a version"},
+		{name: "pinned tag", policy: ImagePolicy{ForbidLatest: true}, ref: "nginx:1.27"},
+		{name: "untagged digest", policy: ImagePolicy{ForbidLatest: true}, ref: "nginx@" + testDigest},
+		{name: "latest with digest", policy: ImagePolicy{ForbidLatest: true}, ref: "nginx:latest@" + testDigest, want: "must not use the latest tag"},
+		{name: "digest required", policy: ImagePolicy{RequireDigest: true}, ref: "nginx:1.27", want: "image nginx:1.27 must be pinned to a digest such as @sha256:…"},
+		{name: "digest present", policy: ImagePolicy{RequireDigest: true}, ref: "nginx:1.27@" + testDigest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseImageReference(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.policy.Check(ref)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			var pe *ImagePolicyError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Check() error = %v, want an *ImagePolicyError", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Check() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImagePolicyForStage(t *testing.T) {
+	tests := []struct {
+		stage string
+		want  ImagePolicy
+	}{
+		{stage: "dev", want: ImagePolicy{ForbidLatest: true}},
+		{stage: "staging", want: ImagePolicy{ForbidLatest: true}},
+		{stage: "prod", want: ImagePolicy{ForbidLatest: true, RequireDigest: true}},
+		{stage: "Production", want: ImagePolicy{ForbidLatest: true, RequireDigest: true}},
+	}
+
+	for _, tt := range tests {
+		if got := ImagePolicyForStage(tt.stage); got != tt.want {
+			t.Errorf("ImagePolicyForStage(%q) = %+v, want %+v", tt.stage, got, tt.want)
+		}
+	}
+}
+
+func TestImageReferenceSchemaPattern(t *testing.T) {
+	pattern := regexp.MustCompile(imageReferenceSchemaPattern)
+	for _, ref := range []string{
+		"nginx", "nginx:1.27", "bitnami/redis:7.2", "localhost:5000/app", "localhost/my_app__v2/web-server",
+		"ghcr.io/tacokumo/portal:v1.2.0-rc.1", "nginx@" + testDigest, "registry.example.com:443/team/app:1.0@" + testDigest,
+		"nginx::latest", "nginx:", "UPPER/case", "ghcr.io/Tacokumo/portal", "ghcr.io//portal", "app-/web", "nginx@sha256:abc",
+	} {
+		_, err := ParseImageReference(ref)
+		if got, want := pattern.MatchString(ref), err == nil; got != want {
+			t.Errorf("schema pattern matches %q = %v, want %v", ref, got, want)
+		}
+	}
+}
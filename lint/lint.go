@@ -0,0 +1,210 @@
+// Package lint checks chart values against platform policies that go beyond
+// the well-formedness checked by each chart's Validate method
+package lint
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+// Severity ranks findings
+type Severity int
+
+// Severities from least to most severe
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+var severityNames = map[Severity]string{
+	Info:    "info",
+	Warning: "warning",
+	Error:   "error",
+}
+
+// String returns the lower case name of the severity
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// ParseSeverity parses the name of a severity
+func ParseSeverity(name string) (Severity, error) {
+	for s, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q; want info, warning or error", name)
+}
+
+// MarshalJSON encodes the severity by name
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Violation is a value that breaks a rule
+type Violation struct {
+	// Path is the YAML path of the value (e.g. main.resources.limits)
+	Path    string
+	Message string
+}
+
+// Rule is a platform policy checked against the values of a chart
+type Rule interface {
+	// ID identifies the rule in findings, rule selections and ignore comments
+	ID() string
+
+	// Description explains what the rule checks
+	Description() string
+
+	// Severity is the severity of the findings of the rule
+	Severity() Severity
+
+	// Check returns the violations of the rule in values, a chart's *Values.
+	// Rules return nothing for the values of charts they do not cover.
+	Check(values any) []Violation
+}
+
+// NewRule returns a rule that runs check against the values of every chart
+func NewRule(id, description string, severity Severity, check func(values any) []Violation) Rule {
+	return &funcRule{id: id, description: description, severity: severity, check: check}
+}
+
+// funcRule is a rule implemented by a function
+type funcRule struct {
+	id          string
+	description string
+	severity    Severity
+	check       func(values any) []Violation
+}
+
+func (r *funcRule) ID() string                   { return r.id }
+func (r *funcRule) Description() string          { return r.description }
+func (r *funcRule) Severity() Severity           { return r.severity }
+func (r *funcRule) Check(values any) []Violation { return r.check(values) }
+
+// Finding is a rule violation located in the values files
+type Finding struct {
+	RuleID   string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+
+	// Location of the value in the values files, when it could be found
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// String formats the finding as file:line:column: severity: path: message [rule]
+func (f Finding) String() string {
+	var b strings.Builder
+	if f.File != "" {
+		b.WriteString(f.File)
+		if f.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", f.Line, f.Column)
+		}
+		b.WriteString(": ")
+	}
+	fmt.Fprintf(&b, "%s: %s: %s [%s]", f.Severity, f.Path, f.Message, f.RuleID)
+	return b.String()
+}
+
+// Select returns the rules whose ID is listed in enable, or all rules when
+// enable is empty, minus the rules listed in disable. Unknown IDs are errors
+// so that typos do not silently turn rules off.
+func Select(rules []Rule, enable, disable []string) ([]Rule, error) {
+	known := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		known[r.ID()] = true
+	}
+	for _, id := range slices.Concat(enable, disable) {
+		if !known[id] {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+	}
+
+	var selected []Rule
+	for _, r := range rules {
+		if (len(enable) == 0 || slices.Contains(enable, r.ID())) && !slices.Contains(disable, r.ID()) {
+			selected = append(selected, r)
+		}
+	}
+	return selected, nil
+}
+
+// Lint runs rules against values and locates the findings in docs, the values
+// files values was decoded from. Findings whose path, or a parent of it,
+// carries a "# helmvalues:ignore RULE" comment in any of docs are dropped; a
+// comment at the top of a file that is followed by a blank line applies to the
+// whole file. Findings are sorted by severity, most severe first.
+func Lint(rules []Rule, values any, docs ...*helmcharts.Document) []Finding {
+	var findings []Finding
+	for _, r := range rules {
+		for _, v := range r.Check(values) {
+			if ignored(r.ID(), v.Path, docs) {
+				continue
+			}
+			f := Finding{RuleID: r.ID(), Severity: r.Severity(), Path: v.Path, Message: v.Message}
+			f.File, f.Line, f.Column = helmcharts.LocatePath(v.Path, docs...)
+			findings = append(findings, f)
+		}
+	}
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Compare(b.Severity, a.Severity)
+	})
+	return findings
+}
+
+// LintChart merges overlays onto the chart's values.yaml, validates the result
+// and lints it. Invalid values are not linted; check the report first.
+func LintChart(c *helmcharts.Chart, rules []Rule, overlays ...*helmcharts.Document) ([]Finding, *helmcharts.ValidationReport, error) {
+	values, report, err := c.Validate(overlays...)
+	if err != nil || !report.Valid() {
+		return nil, report, err
+	}
+	base, err := helmcharts.ParseDocument(c.ValuesFile(), c.ValuesYAML)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Lint(rules, values, append([]*helmcharts.Document{base}, overlays...)...), report, nil
+}
+
+// ignoreDirective starts a comment that suppresses rules
+const ignoreDirective = "helmvalues:ignore"
+
+// ignored reports whether a comment on path or its parents suppresses rule
+func ignored(rule, path string, docs []*helmcharts.Document) bool {
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, comment := range doc.Comments(path) {
+			for _, line := range strings.Split(comment, "\n") {
+				line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+				ids, ok := strings.CutPrefix(line, ignoreDirective)
+				if !ok {
+					continue
+				}
+				if slices.Contains(strings.FieldsFunc(ids, isIDSeparator), rule) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isIDSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t'
+}
@@ -0,0 +1,177 @@
+package lint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+type lintTestValues struct {
+	Image    string `yaml:"image"`
+	Replicas int    `yaml:"replicas"`
+}
+
+var (
+	lintTestImageRule = NewRule("test-image", "image must be set", Error, func(values any) []Violation {
+		if v, ok := values.(*lintTestValues); ok && v.Image == "" {
+			return []Violation{{Path: "image", Message: "image is not set"}}
+		}
+		return nil
+	})
+	lintTestReplicasRule = NewRule("test-replicas", "two replicas", Info, func(values any) []Violation {
+		if v, ok := values.(*lintTestValues); ok && v.Replicas < 2 {
+			return []Violation{{Path: "replicas", Message: "one replica"}}
+		}
+		return nil
+	})
+	lintTestRules = []Rule{lintTestReplicasRule, lintTestImageRule}
+)
+
+func mustParseDocument(t *testing.T, file, data string) *helmcharts.Document {
+	t.Helper()
+	doc, err := helmcharts.ParseDocument(file, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestLint(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", "image: \"\"\nreplicas: 1\n")
+	findings := Lint(lintTestRules, &lintTestValues{Replicas: 1}, base)
+
+	// The most severe findings come first
+	want := []string{
+		`values.yaml:1:1: error: image: image is not set [test-image]`,
+		`values.yaml:2:1: info: replicas: one replica [test-replicas]`,
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		if got := findings[i].String(); got != w {
+			t.Errorf("finding %d = %q, want %q", i, got, w)
+		}
+	}
+
+	// Values of other types are not checked
+	if findings := Lint(lintTestRules, &struct{}{}, base); len(findings) != 0 {
+		t.Errorf("Lint() = %v, want no findings", findings)
+	}
+}
+
+func TestLintIgnoreComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		want   []string
+	}{
+		{
+			name:   "line comment",
+			values: "image: \"\" # helmvalues:ignore test-image\nreplicas: 1\n",
+			want:   []string{"test-replicas"},
+		},
+		{
+			name:   "head comment with several rules",
+			values: "# helmvalues:ignore test-replicas, test-image\nimage: \"\"\nreplicas: 1\n",
+			want:   []string{"test-replicas"},
+		},
+		{
+			name:   "file comment",
+			values: "# helmvalues:ignore test-replicas test-image\n\nimage: \"\"\nreplicas: 1\n",
+			want:   nil,
+		},
+		{
+			name:   "other rule",
+			values: "image: \"\" # helmvalues:ignore test-replicas\nreplicas: 1\n",
+			want:   []string{"test-image", "test-replicas"},
+		},
+		{
+			name:   "not a directive",
+			values: "image: \"\" # see helmvalues:ignore test-image\nreplicas: 1\n",
+			want:   []string{"test-image", "test-replicas"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := mustParseDocument(t, "values.yaml", tt.values)
+			var got []string
+			for _, f := range Lint(lintTestRules, &lintTestValues{Replicas: 1}, doc) {
+				got = append(got, f.RuleID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintIgnoreCommentsInOverlays(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", "image: \"\"\nreplicas: 1\n")
+	overlay := mustParseDocument(t, "prod.yaml", "replicas: 1 # helmvalues:ignore test-replicas\n")
+
+	findings := Lint(lintTestRules, &lintTestValues{Replicas: 1}, base, overlay)
+	if len(findings) != 1 || findings[0].RuleID != "test-image" {
+		t.Errorf("Lint() = %v, want only test-image", findings)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		enable  []string
+		disable []string
+		want    string
+		wantErr string
+	}{
+		{name: "all", want: "test-replicas,test-image"},
+		{name: "enable", enable: []string{"test-image"}, want: "test-image"},
+		{name: "disable", disable: []string{"test-image"}, want: "test-replicas"},
+		{name: "unknown", disable: []string{"test-imag"}, wantErr: `unknown lint rule "test-imag"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := Select(lintTestRules, tt.enable, tt.disable)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Select() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, r := range rules {
+				ids = append(ids, r.ID())
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("Select() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for _, s := range []Severity{Info, Warning, Error} {
+		parsed, err := ParseSeverity(strings.ToUpper(s.String()))
+		if err != nil || parsed != s {
+			t.Errorf("ParseSeverity(%s) = %v, %v", s, parsed, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("ParseSeverity(fatal) should fail")
+	}
+
+	data, err := json.Marshal(Finding{RuleID: "r", Severity: Warning, Path: "a", Message: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"rule":"r","severity":"warning","path":"a","message":"m"}`; string(data) != want {
+		t.Errorf("json = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,300 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+	portal_controller_kubernetes "github.com/tacokumo/helm-charts/charts/portal-controller-kubernetes"
+	tacokumo_application "github.com/tacokumo/helm-charts/charts/tacokumo-application"
+	tacokumo_portal "github.com/tacokumo/helm-charts/charts/tacokumo-portal"
+	tacokumo_portal_proxy "github.com/tacokumo/helm-charts/charts/tacokumo-portal-proxy"
+)
+
+// BuiltinRules returns the platform rules for the charts of this repository
+func BuiltinRules() []Rule {
+	return []Rule{
+		ImageTagRule,
+		ResourcesRule,
+		ProbesRule,
+		PrivilegeRule,
+		IngressTLSRule,
+		ReplicasRule,
+	}
+}
+
+// ImageTagRule requires images to be pinned to a version
+var ImageTagRule = NewRule("image-tag", "Images must be pinned to a version tag or digest, not latest", Warning, func(values any) []Violation {
+	switch v := values.(type) {
+	case *tacokumo_application.Values:
+		return checkImageRef("main.image", v.Main.Image)
+	case *tacokumo_portal.Values:
+		return checkImageTag("api.image.tag", v.API.Image.Tag)
+	case *tacokumo_portal_proxy.Values:
+		return checkImageTag("portalProxy.image.tag", v.PortalProxy.Image.Tag)
+	case *portal_controller_kubernetes.Values:
+		return checkImageTag("controller.managerContainer.image.tag", v.Controller.ManagerContainer.Image.Tag)
+	}
+	return nil
+})
+
+// checkImageRef checks the tag of an image reference such as nginx:1.27.
+// References pinned to a digest pass; malformed ones are left to Validate.
+func checkImageRef(path, ref string) []Violation {
+	r, err := helmcharts.ParseImageReference(ref)
+	if err != nil || r.Digest != "" {
+		return nil
+	}
+	return checkImageTag(path, r.Tag)
+}
+
+func checkImageTag(path, tag string) []Violation {
+	switch tag {
+	case "":
+		return []Violation{{Path: path, Message: "image has no tag, so latest is pulled"}}
+	case "latest":
+		return []Violation{{Path: path, Message: "image tag latest changes without notice; pin a version"}}
+	}
+	return nil
+}
+
+// ResourcesRule requires containers to request resources and limit memory
+var ResourcesRule = NewRule("resources", "Containers must request CPU and memory and limit memory", Warning, func(values any) []Violation {
+	switch v := values.(type) {
+	case *tacokumo_application.Values:
+		r := v.Main.Resources
+		return checkResources("main.resources", r.Requests.CPU, r.Requests.Memory, r.Limits.Memory)
+	case *tacokumo_portal.Values:
+		r := v.API.Resources
+		return checkResources("api.resources", r.Requests.CPU, r.Requests.Memory, r.Limits.Memory)
+	case *tacokumo_portal_proxy.Values:
+		r := v.PortalProxy.Resources
+		return checkResources("portalProxy.resources", r.Requests.CPU, r.Requests.Memory, r.Limits.Memory)
+	case *portal_controller_kubernetes.Values:
+		r := v.Controller.ManagerContainer.Resources
+		return checkResources("controller.managerContainer.resources", r.Requests.CPU, r.Requests.Memory, r.Limits.Memory)
+	}
+	return nil
+})
+
+func checkResources(path, requestsCPU, requestsMemory, limitsMemory string) []Violation {
+	var violations []Violation
+	for _, r := range []struct{ field, value, message string }{
+		{"requests.cpu", requestsCPU, "CPU request is not set, so the pod may be scheduled on a full node"},
+		{"requests.memory", requestsMemory, "memory request is not set, so the pod may be scheduled on a full node"},
+		{"limits.memory", limitsMemory, "memory limit is not set, so the container can use all memory of its node"},
+	} {
+		if r.value == "" {
+			violations = append(violations, Violation{Path: path + "." + r.field, Message: r.message})
+		}
+	}
+	return violations
+}
+
+// ProbesRule requires containers to define liveness and readiness probes
+var ProbesRule = NewRule("probes", "Containers must define liveness and readiness probes", Warning, func(values any) []Violation {
+	var probes []probe
+	switch v := values.(type) {
+	case *tacokumo_application.Values:
+		probes = []probe{
+			{"main.livenessProbe", hasHandler(v.Main.LivenessProbe.HTTPGet, v.Main.LivenessProbe.TCPSocket, v.Main.LivenessProbe.Exec)},
+			{"main.readinessProbe", hasHandler(v.Main.ReadinessProbe.HTTPGet, v.Main.ReadinessProbe.TCPSocket, v.Main.ReadinessProbe.Exec)},
+		}
+	case *tacokumo_portal.Values:
+		probes = []probe{
+			{"api.livenessProbe", hasHandler(v.API.LivenessProbe.HTTPGet, v.API.LivenessProbe.TCPSocket, v.API.LivenessProbe.Exec)},
+			{"api.readinessProbe", hasHandler(v.API.ReadinessProbe.HTTPGet, v.API.ReadinessProbe.TCPSocket, v.API.ReadinessProbe.Exec)},
+		}
+	case *tacokumo_portal_proxy.Values:
+		p := v.PortalProxy
+		probes = []probe{
+			{"portalProxy.livenessProbe", hasHandler(p.LivenessProbe.HTTPGet, p.LivenessProbe.TCPSocket, p.LivenessProbe.Exec)},
+			{"portalProxy.readinessProbe", hasHandler(p.ReadinessProbe.HTTPGet, p.ReadinessProbe.TCPSocket, p.ReadinessProbe.Exec)},
+		}
+	case *portal_controller_kubernetes.Values:
+		c := v.Controller.ManagerContainer
+		probes = []probe{
+			{"controller.managerContainer.livenessProbe", c.LivenessProbe.HTTPGet.Path != ""},
+			{"controller.managerContainer.readinessProbe", c.ReadinessProbe.HTTPGet.Path != ""},
+		}
+	}
+
+	var violations []Violation
+	for _, p := range probes {
+		if !p.defined {
+			violations = append(violations, Violation{Path: p.path, Message: "probe is not defined"})
+		}
+	}
+	return violations
+})
+
+// probe records whether the probe at path has a handler
+type probe struct {
+	path    string
+	defined bool
+}
+
+// hasHandler reports whether any of the handlers of a probe is set
+func hasHandler[H, T, E any](httpGet *H, tcpSocket *T, exec *E) bool {
+	return httpGet != nil || tcpSocket != nil || exec != nil
+}
+
+// PrivilegeRule forbids containers that run as root or can gain privileges
+var PrivilegeRule = NewRule("privilege", "Containers must not run as root, be privileged or allow privilege escalation", Error, func(values any) []Violation {
+	var contexts []securityContext
+	switch v := values.(type) {
+	case *tacokumo_portal.Values:
+		for _, c := range []struct {
+			path string
+			sc   tacokumo_portal.SecurityContext
+		}{
+			{"api.securityContext", v.API.SecurityContext},
+			{"api.containerSecurityContext", v.API.ContainerSecurityContext},
+		} {
+			contexts = append(contexts, securityContext{
+				path:                     c.path,
+				runAsUser:                c.sc.RunAsUser,
+				runAsNonRoot:             c.sc.RunAsNonRoot,
+				allowPrivilegeEscalation: c.sc.AllowPrivilegeEscalation,
+			})
+		}
+	case *tacokumo_portal_proxy.Values:
+		sc := v.PortalProxy.SecurityContext
+		contexts = append(contexts, securityContext{
+			path:                     "portalProxy.securityContext",
+			runAsUser:                sc.RunAsUser,
+			runAsNonRoot:             sc.RunAsNonRoot,
+			allowPrivilegeEscalation: sc.AllowPrivilegeEscalation,
+		})
+	case *portal_controller_kubernetes.Values:
+		pod, container := v.Controller.SecurityContext, v.Controller.ManagerContainer.SecurityContext
+		contexts = append(contexts,
+			securityContext{
+				path:         "controller.securityContext",
+				runAsUser:    pod.RunAsUser,
+				runAsNonRoot: pod.RunAsNonRoot,
+			},
+			securityContext{
+				path:                     "controller.managerContainer.securityContext",
+				runAsUser:                container.RunAsUser,
+				runAsNonRoot:             container.RunAsNonRoot,
+				allowPrivilegeEscalation: container.AllowPrivilegeEscalation,
+				privileged:               container.Privileged,
+			},
+		)
+	}
+
+	var violations []Violation
+	for _, sc := range contexts {
+		violations = append(violations, sc.check()...)
+	}
+	return violations
+})
+
+// securityContext holds the privilege settings shared by the security contexts of the charts
+type securityContext struct {
+	path                     string
+	runAsUser                *int64
+	runAsNonRoot             *bool
+	allowPrivilegeEscalation *bool
+	privileged               *bool
+}
+
+func (sc securityContext) check() []Violation {
+	var violations []Violation
+	if sc.runAsUser != nil && *sc.runAsUser == 0 {
+		violations = append(violations, Violation{Path: sc.path + ".runAsUser", Message: "container runs as root"})
+	}
+	if sc.runAsNonRoot != nil && !*sc.runAsNonRoot {
+		violations = append(violations, Violation{Path: sc.path + ".runAsNonRoot", Message: "container is allowed to run as root"})
+	}
+	if sc.allowPrivilegeEscalation != nil && *sc.allowPrivilegeEscalation {
+		violations = append(violations, Violation{Path: sc.path + ".allowPrivilegeEscalation", Message: "container can gain more privileges than its parent process"})
+	}
+	if sc.privileged != nil && *sc.privileged {
+		violations = append(violations, Violation{Path: sc.path + ".privileged", Message: "container runs privileged"})
+	}
+	return violations
+}
+
+// IngressTLSRule requires every ingress host to be served over TLS
+var IngressTLSRule = NewRule("ingress-tls", "Ingress hosts must be covered by a TLS entry", Warning, func(values any) []Violation {
+	switch v := values.(type) {
+	case *tacokumo_application.Values:
+		ing := v.Main.Ingress
+		if !ing.Enabled {
+			return nil
+		}
+		hosts := make([]string, len(ing.Hosts))
+		for i, h := range ing.Hosts {
+			hosts[i] = h.Host
+		}
+		var tlsHosts []string
+		for _, tls := range ing.TLS {
+			tlsHosts = append(tlsHosts, tls.Hosts...)
+		}
+		return checkIngressTLS("main.ingress.hosts", hosts, tlsHosts)
+	case *tacokumo_portal_proxy.Values:
+		ing := v.PortalProxy.Ingress
+		if !ing.Enabled {
+			return nil
+		}
+		hosts := make([]string, len(ing.Hosts))
+		for i, h := range ing.Hosts {
+			hosts[i] = h.Host
+		}
+		var tlsHosts []string
+		for _, tls := range ing.TLS {
+			tlsHosts = append(tlsHosts, tls.Hosts...)
+		}
+		return checkIngressTLS("portalProxy.ingress.hosts", hosts, tlsHosts)
+	}
+	return nil
+})
+
+func checkIngressTLS(path string, hosts, tlsHosts []string) []Violation {
+	var violations []Violation
+	for i, host := range hosts {
+		if !coversHost(tlsHosts, host) {
+			violations = append(violations, Violation{
+				Path:    fmt.Sprintf("%s[%d].host", path, i),
+				Message: fmt.Sprintf("host %s is not covered by any TLS entry", host),
+			})
+		}
+	}
+	return violations
+}
+
+// coversHost reports whether host matches one of patterns, which may be wildcards such as *.example.com
+func coversHost(patterns []string, host string) bool {
+	for _, p := range patterns {
+		if p == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if label, rest, found := strings.Cut(host, "."); found && label != "" && rest == suffix {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReplicasRule recommends running more than one replica of user facing workloads
+var ReplicasRule = NewRule("replicas", "User facing workloads should be able to run more than one replica", Info, func(values any) []Violation {
+	switch v := values.(type) {
+	case *tacokumo_application.Values:
+		if v.Main.HPA.MaxReplicas < 2 {
+			return []Violation{{Path: "main.hpa.maxReplicas", Message: "at most one replica runs, so the application is down during rollouts and node failures"}}
+		}
+	case *tacokumo_portal.Values:
+		if v.API.HPA.Enabled != nil && *v.API.HPA.Enabled && v.API.HPA.MaxReplicas < 2 {
+			return []Violation{{Path: "api.hpa.maxReplicas", Message: "at most one replica runs, so the API is down during rollouts and node failures"}}
+		}
+	case *tacokumo_portal_proxy.Values:
+		if v.PortalProxy.ReplicaCount < 2 {
+			return []Violation{{Path: "portalProxy.replicaCount", Message: "a single replica is down during rollouts and node failures"}}
+		}
+	}
+	return nil
+})
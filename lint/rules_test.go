@@ -0,0 +1,229 @@
+package lint
+
+import (
+	"slices"
+	"testing"
+
+	helmcharts "github.com/tacokumo/helm-charts"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   string
+		rule    Rule
+		overlay string
+		want    []string
+	}{
+		{
+			name:  "application image without tag",
+			chart: "tacokumo-application",
+			rule:  ImageTagRule,
+			overlay: `main:
+  image: registry.example.com:5000/web
+`,
+			want: []string{"prod.yaml:2:3: warning: main.image: image has no tag, so latest is pulled [image-tag]"},
+		},
+		{
+			name:    "application image with digest",
+			chart:   "tacokumo-application",
+			rule:    ImageTagRule,
+			overlay: "main:\n  image: nginx@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+		},
+		{
+			name:    "portal latest tag",
+			chart:   "tacokumo-portal",
+			rule:    ImageTagRule,
+			overlay: "api:\n  image:\n    tag: latest\n",
+			want:    []string{"prod.yaml:3:5: warning: api.image.tag: image tag latest changes without notice; pin a version [image-tag]"},
+		},
+		{
+			name:    "controller pinned tag",
+			chart:   "portal-controller-kubernetes",
+			rule:    ImageTagRule,
+			overlay: "controller:\n  managerContainer:\n    image:\n      tag: v1.2.3\n",
+		},
+		{
+			name:  "application missing memory limit",
+			chart: "tacokumo-application",
+			rule:  ResourcesRule,
+			overlay: `main:
+  resources:
+    requests:
+      cpu: 100m
+      memory: 128Mi
+`,
+			want: []string{"prod.yaml:2:3: warning: main.resources.limits.memory: memory limit is not set, so the container can use all memory of its node [resources]"},
+		},
+		{
+			name:    "proxy without requests",
+			chart:   "tacokumo-portal-proxy",
+			rule:    ResourcesRule,
+			overlay: "portalProxy:\n  resources:\n    requests: null\n",
+			want: []string{
+				"charts/tacokumo-portal-proxy/values.yaml:45:7: warning: portalProxy.resources.requests.cpu: CPU request is not set, so the pod may be scheduled on a full node [resources]",
+				"charts/tacokumo-portal-proxy/values.yaml:46:7: warning: portalProxy.resources.requests.memory: memory request is not set, so the pod may be scheduled on a full node [resources]",
+			},
+		},
+		{
+			name:  "application with probes",
+			chart: "tacokumo-application",
+			rule:  ProbesRule,
+			overlay: `main:
+  livenessProbe:
+    httpGet: {path: /healthz, port: 80}
+  readinessProbe:
+    tcpSocket: {port: 80}
+`,
+		},
+		{
+			name:    "portal without readiness probe",
+			chart:   "tacokumo-portal",
+			rule:    ProbesRule,
+			overlay: "api:\n  readinessProbe: null\n",
+			want:    []string{"prod.yaml:2:3: warning: api.readinessProbe: probe is not defined [probes]"},
+		},
+		{
+			name:    "portal running as root",
+			chart:   "tacokumo-portal",
+			rule:    PrivilegeRule,
+			overlay: "api:\n  securityContext:\n    runAsUser: 0\n    runAsNonRoot: false\n",
+			want: []string{
+				"prod.yaml:3:5: error: api.securityContext.runAsUser: container runs as root [privilege]",
+				"prod.yaml:4:5: error: api.securityContext.runAsNonRoot: container is allowed to run as root [privilege]",
+			},
+		},
+		{
+			name:    "controller privileged container",
+			chart:   "portal-controller-kubernetes",
+			rule:    PrivilegeRule,
+			overlay: "controller:\n  managerContainer:\n    securityContext:\n      privileged: true\n      allowPrivilegeEscalation: true\n",
+			want: []string{
+				"prod.yaml:5:7: error: controller.managerContainer.securityContext.allowPrivilegeEscalation: container can gain more privileges than its parent process [privilege]",
+				"prod.yaml:4:7: error: controller.managerContainer.securityContext.privileged: container runs privileged [privilege]",
+			},
+		},
+		{
+			name:    "proxy defaults are unprivileged",
+			chart:   "tacokumo-portal-proxy",
+			rule:    PrivilegeRule,
+			overlay: "{}\n",
+		},
+		{
+			name:  "application ingress host without TLS",
+			chart: "tacokumo-application",
+			rule:  IngressTLSRule,
+			overlay: `main:
+  ingress:
+    enabled: true
+    className: nginx
+    hosts:
+      - host: app.tacokumo.dev
+        paths: [{path: /, pathType: Prefix}]
+      - host: www.tacokumo.dev
+        paths: [{path: /, pathType: Prefix}]
+    tls:
+      - secretName: app-tls
+        hosts: [app.tacokumo.dev]
+`,
+			want: []string{"prod.yaml:8:9: warning: main.ingress.hosts[1].host: host www.tacokumo.dev is not covered by any TLS entry [ingress-tls]"},
+		},
+		{
+			name:  "proxy ingress with TLS",
+			chart: "tacokumo-portal-proxy",
+			rule:  IngressTLSRule,
+			overlay: `portalProxy:
+  ingress:
+    enabled: true
+    className: nginx
+    hosts:
+      - host: proxy.tacokumo.dev
+        paths: [{path: /, pathType: Prefix}]
+    tls:
+      - secretName: proxy-tls
+        hosts: [proxy.tacokumo.dev]
+`,
+		},
+		{
+			name:    "application single replica",
+			chart:   "tacokumo-application",
+			rule:    ReplicasRule,
+			overlay: "main:\n  hpa:\n    maxReplicas: 1\n",
+			want:    []string{"prod.yaml:3:5: info: main.hpa.maxReplicas: at most one replica runs, so the application is down during rollouts and node failures [replicas]"},
+		},
+		{
+			name:    "proxy with replicas",
+			chart:   "tacokumo-portal-proxy",
+			rule:    ReplicasRule,
+			overlay: "portalProxy:\n  replicaCount: 2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := helmcharts.DefaultRegistry.Lookup(tt.chart)
+			if err != nil {
+				t.Fatal(err)
+			}
+			findings, report, err := LintChart(c, []Rule{tt.rule}, mustParseDocument(t, "prod.yaml", tt.overlay))
+			if err != nil {
+				t.Fatalf("LintChart() error = %v", err)
+			}
+			if !report.Valid() {
+				t.Fatalf("values are invalid:\n%s", report)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findings =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoversHost(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		host     string
+		want     bool
+	}{
+		{[]string{"app.tacokumo.dev"}, "app.tacokumo.dev", true},
+		{[]string{"app.tacokumo.dev"}, "www.tacokumo.dev", false},
+		{[]string{"*.tacokumo.dev"}, "app.tacokumo.dev", true},
+		{[]string{"*.tacokumo.dev"}, "tacokumo.dev", false},
+		{[]string{"*.tacokumo.dev"}, "a.b.tacokumo.dev", false},
+		{nil, "app.tacokumo.dev", false},
+	}
+
+	for _, tt := range tests {
+		if got := coversHost(tt.patterns, tt.host); got != tt.want {
+			t.Errorf("coversHost(%q, %q) = %v, want %v", tt.patterns, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestBuiltinRulesCoverEveryChart(t *testing.T) {
+	// Every chart is checked by at least one built-in rule
+	for _, c := range helmcharts.DefaultRegistry.Charts() {
+		covered := false
+		empty := c.NewValues()
+		for _, r := range BuiltinRules() {
+			if len(r.Check(empty)) > 0 {
+				covered = true
+			}
+		}
+		if !covered {
+			t.Errorf("no built-in rule checks the values of %s", c.Name)
+		}
+	}
+
+	ids := make(map[string]bool)
+	for _, r := range BuiltinRules() {
+		if ids[r.ID()] {
+			t.Errorf("duplicate rule ID %q", r.ID())
+		}
+		ids[r.ID()] = true
+	}
+}
@@ -0,0 +1,176 @@
+package helmcharts
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadValues reads the values file at path and the optional overlay files,
+// merges them as Helm does for repeated -f flags, strictly decodes the result
+// into a new T and validates it. Unknown keys and validation failures are both
+// returned in the report; the error is only set when a file cannot be read or
+// decoded.
+func LoadValues[T any, PT interface {
+	*T
+	Validatable
+}](path string, overlays ...string) (*T, *ValidationReport, error) {
+	var docs []*Document
+	for _, p := range append([]string{path}, overlays...) {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		doc, err := ParseDocument(p, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return MergeValues[T, PT](docs[0], docs[1:]...)
+}
+
+// DecodeStrict decodes the document into v and reports every key that does not
+// map to a field of v, instead of silently dropping it
+func (d *Document) DecodeStrict(v any) (*ValidationReport, error) {
+	report := &ValidationReport{}
+	if d.Root == nil || len(d.Root.Content) == 0 {
+		return report, nil
+	}
+	checkKnownFields(d.File, d.Root.Content[0], reflect.TypeOf(v), nil, report)
+	if err := d.Decode(v); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+var yamlUnmarshalerType = reflect.TypeFor[yaml.Unmarshaler]()
+
+// checkKnownFields walks node alongside t and records keys that t cannot hold
+func checkKnownFields(file string, node *yaml.Node, t reflect.Type, path yamlPathSegments, report *ValidationReport) {
+	node = resolveAlias(node)
+	t = derefType(t)
+	if t == nil || reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+			keyPath := appendPath(path, pathSegment{name: key.Value})
+			f, ok := fields[key.Value]
+			if !ok {
+				report.Issues = append(report.Issues, ValidationIssue{
+					Path:    keyPath.String(),
+					File:    file,
+					Line:    key.Line,
+					Column:  key.Column,
+					Tag:     "unknown_field",
+					Message: unknownFieldMessage(key.Value, t, fields),
+				})
+				continue
+			}
+			checkKnownFields(file, value, f.Type, keyPath, report)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			checkKnownFields(file, item, t.Elem(), appendPath(path, pathSegment{index: i, item: true}), report)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			seg := pathSegment{name: node.Content[i].Value, key: true}
+			checkKnownFields(file, node.Content[i+1], t.Elem(), appendPath(path, seg), report)
+		}
+	}
+}
+
+func appendPath(path yamlPathSegments, seg pathSegment) yamlPathSegments {
+	out := make(yamlPathSegments, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, seg)
+}
+
+// yamlFields returns the fields of struct type t keyed by their YAML key,
+// including the fields of inlined structs
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for _, f := range structFields(t) {
+		fields[yamlFieldName(f)] = f
+	}
+	return fields
+}
+
+// unknownFieldMessage describes an unknown key, suggesting the key that was
+// probably meant: a similarly spelled sibling, or the same key one level down
+func unknownFieldMessage(key string, t reflect.Type, fields map[string]reflect.StructField) string {
+	msg := fmt.Sprintf("unknown key %q in %s", key, t.Name())
+	if suggestion := similarKey(key, fields); suggestion != "" {
+		return fmt.Sprintf("%s; did you mean %q?", msg, suggestion)
+	}
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		ft := derefType(fields[name].Type)
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+		if _, ok := yamlFields(ft)[key]; ok {
+			return fmt.Sprintf("%s; did you mean %q?", msg, name+"."+key)
+		}
+	}
+	return msg
+}
+
+// similarKey returns the known key closest to key, if any is close enough
+func similarKey(key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", -1
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(name))
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	if bestDistance < 0 || bestDistance > max(2, len(key)/3) {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
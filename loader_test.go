@@ -0,0 +1,130 @@
+package helmcharts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeStrictReportsUnknownKeys(t *testing.T) {
+	data := []byte(`service:
+  tpye: ClusterIP
+  port: 80
+ingress:
+  enabled: false
+  hosts:
+    - host: example.com
+      path: /
+  extra: true
+`)
+
+	doc, err := ParseDocument("values.yaml", data)
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	var values reportTestValues
+	report, err := doc.DecodeStrict(&values)
+	if err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+
+	want := []ValidationIssue{
+		{
+			Path:    "service.tpye",
+			Line:    2,
+			Column:  3,
+			Message: `unknown key "tpye" in Service; did you mean "type"?`,
+		},
+		{
+			Path:    "ingress.hosts[0].path",
+			Line:    8,
+			Column:  7,
+			Message: `unknown key "path" in IngressHost; did you mean "paths"?`,
+		},
+		{
+			Path:    "ingress.extra",
+			Line:    9,
+			Column:  3,
+			Message: `unknown key "extra" in Ingress`,
+		},
+	}
+
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d:\n%s", len(report.Issues), len(want), report)
+	}
+	for i, w := range want {
+		got := report.Issues[i]
+		if got.Path != w.Path || got.Line != w.Line || got.Column != w.Column || got.Message != w.Message {
+			t.Errorf("issue %d = %+v, want %+v", i, got, w)
+		}
+		if got.Tag != "unknown_field" || got.File != "values.yaml" {
+			t.Errorf("issue %d tag = %q, file = %q", i, got.Tag, got.File)
+		}
+	}
+
+	// Known keys are still decoded
+	if values.Service.Port != 80 || values.Ingress.Hosts[0].Host != "example.com" {
+		t.Errorf("decoded values = %+v", values)
+	}
+}
+
+func TestDecodeStrictSuggestsMisplacedKeys(t *testing.T) {
+	type probeAction struct {
+		Path string `yaml:"path"`
+		Port int    `yaml:"port"`
+	}
+	type probe struct {
+		HTTPGet        *probeAction `yaml:"httpGet,omitempty"`
+		PeriodSeconds  int          `yaml:"periodSeconds"`
+		TimeoutSeconds int          `yaml:"timeoutSeconds"`
+	}
+
+	doc, err := ParseDocument("values.yaml", []byte("port: 8080\nperiodSeconds: 10\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	var p probe
+	report, err := doc.DecodeStrict(&p)
+	if err != nil {
+		t.Fatalf("DecodeStrict() error = %v", err)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
+	}
+	if want := `unknown key "port" in probe; did you mean "httpGet.port"?`; report.Issues[0].Message != want {
+		t.Errorf("Message = %q, want %q", report.Issues[0].Message, want)
+	}
+}
+
+func TestLoadValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	data := []byte("service:\n  type: ClusterIP\n  port: 0\n  portt: 80\n")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	values, report, err := LoadValues[reportTestValues](path)
+	if err != nil {
+		t.Fatalf("LoadValues() error = %v", err)
+	}
+	if values.Service.Type != "ClusterIP" {
+		t.Errorf("Service.Type = %q, want ClusterIP", values.Service.Type)
+	}
+
+	// Unknown keys are reported before validation failures
+	if len(report.Issues) != 2 {
+		t.Fatalf("got %d issues, want 2:\n%s", len(report.Issues), report)
+	}
+	if report.Issues[0].Tag != "unknown_field" || report.Issues[0].Path != "service.portt" {
+		t.Errorf("issue 0 = %+v, want unknown service.portt", report.Issues[0])
+	}
+	if report.Issues[1].Tag != "min" || report.Issues[1].Path != "service.port" {
+		t.Errorf("issue 1 = %+v, want min failure on service.port", report.Issues[1])
+	}
+
+	if _, _, err := LoadValues[reportTestValues](filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadValues() should fail for a missing file")
+	}
+}
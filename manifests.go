@@ -0,0 +1,238 @@
+package helmcharts
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ObjectMeta represents the metadata of a rendered Kubernetes object
+type ObjectMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// clone returns a copy of o that does not share its labels
+func (o ObjectMeta) clone() ObjectMeta {
+	o.Labels = maps.Clone(o.Labels)
+	return o
+}
+
+// ConfigMap represents a Kubernetes ConfigMap manifest
+type ConfigMap struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   ObjectMeta        `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// Secret represents a Kubernetes Secret manifest. Values are written as
+// stringData, which the API server encodes into data.
+type Secret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   ObjectMeta        `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	StringData map[string]string `yaml:"stringData"`
+}
+
+// EnvVar represents a container environment variable
+type EnvVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty"`
+}
+
+// EnvVarSource represents the source of an environment variable's value
+type EnvVarSource struct {
+	SecretKeyRef *SecretKeyRef `yaml:"secretKeyRef,omitempty"`
+}
+
+// EnvFromSource represents a ConfigMap or Secret whose keys become environment variables
+type EnvFromSource struct {
+	ConfigMapRef *EnvFromReference `yaml:"configMapRef,omitempty"`
+	SecretRef    *EnvFromReference `yaml:"secretRef,omitempty"`
+}
+
+// EnvFromReference names the ConfigMap or Secret of an EnvFromSource
+type EnvFromReference struct {
+	Name string `yaml:"name"`
+}
+
+// ExternalServiceManifests are the manifests rendered from an ExternalServiceConfig
+type ExternalServiceManifests struct {
+	// ConfigMap holds the settings that are not sensitive
+	ConfigMap ConfigMap
+
+	// Secret holds the literal credentials
+	Secret Secret
+
+	// Env holds the credentials given as secretKeyRef, which envFrom cannot
+	// rename; add them to the container's env. They are sorted by name.
+	Env []EnvVar
+}
+
+// RenderExternalServices validates config and renders it into a ConfigMap and
+// a Secret, both named after meta, to be loaded with envFrom (see EnvFrom).
+//
+// Every field becomes one environment variable named after the YAML keys of
+// its section and of the field in upper snake case, e.g. database.host is
+// DATABASE_HOST, redis.poolSize is REDIS_POOL_SIZE and auth.oauthClientId is
+// AUTH_OAUTH_CLIENT_ID. Lists are joined with commas and maps are written as
+// comma separated key=value pairs sorted by key. Fields with a zero value are
+// left out, except booleans, so that applications fall back to their own
+// defaults. Fields tagged sensitive:"true" go to the Secret, or to Env when
+// they reference an existing Secret. The output only depends on config.
+func RenderExternalServices(meta ObjectMeta, config *ExternalServiceConfig) (*ExternalServiceManifests, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &ExternalServiceManifests{
+		ConfigMap: ConfigMap{APIVersion: "v1", Kind: "ConfigMap", Metadata: meta.clone(), Data: map[string]string{}},
+		Secret:    Secret{APIVersion: "v1", Kind: "Secret", Metadata: meta.clone(), Type: "Opaque", StringData: map[string]string{}},
+	}
+	m.collect("", reflect.ValueOf(config).Elem(), false)
+	slices.SortFunc(m.Env, func(a, b EnvVar) int { return strings.Compare(a.Name, b.Name) })
+	return m, nil
+}
+
+// collect adds the environment variables of v, named with the prefix name
+func (m *ExternalServiceManifests) collect(name string, v reflect.Value, sensitive bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if s, ok := v.Interface().(SecretValue); ok {
+		switch {
+		case s.SecretKeyRef != nil:
+			ref := *s.SecretKeyRef
+			m.Env = append(m.Env, EnvVar{Name: name, ValueFrom: &EnvVarSource{SecretKeyRef: &ref}})
+		case s.Value != "":
+			m.Secret.StringData[name] = s.Value
+		}
+		return
+	}
+	if v.Kind() == reflect.Struct {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Tag.Get("yaml") == "-" {
+				continue
+			}
+			m.collect(joinEnvName(name, envName(yamlFieldName(f))), v.Field(i), sensitive || isSensitive(f))
+		}
+		return
+	}
+
+	value, ok := envValue(v)
+	if !ok {
+		return
+	}
+	if sensitive {
+		m.Secret.StringData[name] = value
+	} else {
+		m.ConfigMap.Data[name] = value
+	}
+}
+
+// envValue formats a scalar, list or map field, reporting false for zero values
+func envValue(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if item, ok := envValue(v.Index(i)); ok {
+				items = append(items, item)
+			}
+		}
+		return strings.Join(items, ","), len(items) > 0
+	case reflect.Map:
+		values := make(map[string]string, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if value, ok := envValue(iter.Value()); ok {
+				values[fmt.Sprint(iter.Key().Interface())] = value
+			}
+		}
+		pairs := make([]string, 0, len(values))
+		for _, k := range slices.Sorted(maps.Keys(values)) {
+			pairs = append(pairs, k+"="+values[k])
+		}
+		return strings.Join(pairs, ","), len(pairs) > 0
+	}
+	if v.IsZero() {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+// envName converts a camelCase YAML key into UPPER_SNAKE_CASE, e.g. poolSize
+// into POOL_SIZE and oauthClientId into OAUTH_CLIENT_ID
+func envName(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func joinEnvName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// EnvFrom returns the envFrom entries that load the ConfigMap and the Secret
+func (m *ExternalServiceManifests) EnvFrom() []EnvFromSource {
+	return []EnvFromSource{
+		{ConfigMapRef: &EnvFromReference{Name: m.ConfigMap.Metadata.Name}},
+		{SecretRef: &EnvFromReference{Name: m.Secret.Metadata.Name}},
+	}
+}
+
+// YAML encodes the ConfigMap and the Secret as a multi-document YAML stream
+func (m *ExternalServiceManifests) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, obj := range []any{m.ConfigMap, m.Secret} {
+		if err := enc.Encode(obj); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
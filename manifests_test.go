@@ -0,0 +1,154 @@
+package helmcharts
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderExternalServices(t *testing.T) {
+	meta := ObjectMeta{Name: "portal-external", Namespace: "portal"}
+	got, err := RenderExternalServices(meta, externalTestConfig())
+	if err != nil {
+		t.Fatalf("RenderExternalServices() error = %v", err)
+	}
+
+	wantData := map[string]string{
+		"DATABASE_HOST":                      "db.example.com",
+		"DATABASE_PORT":                      "5432",
+		"DATABASE_DATABASE":                  "portal",
+		"DATABASE_USERNAME":                  "portal",
+		"DATABASE_SSL_MODE":                  "require",
+		"DATABASE_CONN_MAX_LIFETIME":         "30m",
+		"REDIS_HOST":                         "redis.example.com",
+		"REDIS_PORT":                         "6379",
+		"REDIS_POOL_SIZE":                    "10",
+		"REDIS_TLS":                          "false",
+		"AUTH_ENABLED":                       "false",
+		"AUTH_OAUTH_CLIENT_ID":               "portal",
+		"AUTH_SESSION_SECURE":                "false",
+		"CORS_ENABLED":                       "false",
+		"CORS_ALLOW_CREDENTIALS":             "false",
+		"TLS_ENABLED":                        "false",
+		"OPEN_TELEMETRY_ENABLED":             "true",
+		"OPEN_TELEMETRY_SERVICE_NAME":        "portal",
+		"OPEN_TELEMETRY_TRACING_ENABLED":     "false",
+		"OPEN_TELEMETRY_TRACING_SAMPLING":    "0.25",
+		"OPEN_TELEMETRY_METRICS_ENABLED":     "false",
+		"OPEN_TELEMETRY_LOGGING_ENABLED":     "false",
+		"OPEN_TELEMETRY_RESOURCE_ATTRIBUTES": "deployment.environment=prod,team=platform",
+	}
+	if !reflect.DeepEqual(got.ConfigMap.Data, wantData) {
+		t.Errorf("ConfigMap.Data = %v, want %v", got.ConfigMap.Data, wantData)
+	}
+
+	wantSecret := map[string]string{
+		"DATABASE_PASSWORD":      "pg-s3cret",
+		"AUTH_SESSION_SECRET":    "session-s3cret-that-is-32-chars-long",
+		"OPEN_TELEMETRY_HEADERS": "Authorization=Bearer otel-s3cret",
+	}
+	if !reflect.DeepEqual(got.Secret.StringData, wantSecret) {
+		t.Errorf("Secret.StringData = %v, want %v", got.Secret.StringData, wantSecret)
+	}
+
+	wantEnv := []EnvVar{{Name: "REDIS_PASSWORD", ValueFrom: &EnvVarSource{SecretKeyRef: &SecretKeyRef{Name: "redis", Key: "password"}}}}
+	if !reflect.DeepEqual(got.Env, wantEnv) {
+		t.Errorf("Env = %+v, want %+v", got.Env, wantEnv)
+	}
+
+	if !reflect.DeepEqual(got.ConfigMap.Metadata, meta) || !reflect.DeepEqual(got.Secret.Metadata, meta) {
+		t.Errorf("Metadata = %+v, %+v, want %+v", got.ConfigMap.Metadata, got.Secret.Metadata, meta)
+	}
+	wantEnvFrom := []EnvFromSource{
+		{ConfigMapRef: &EnvFromReference{Name: "portal-external"}},
+		{SecretRef: &EnvFromReference{Name: "portal-external"}},
+	}
+	if !reflect.DeepEqual(got.EnvFrom(), wantEnvFrom) {
+		t.Errorf("EnvFrom() = %+v, want %+v", got.EnvFrom(), wantEnvFrom)
+	}
+}
+
+func TestRenderExternalServicesIsDeterministic(t *testing.T) {
+	meta := ObjectMeta{Name: "portal-external", Labels: map[string]string{"app": "portal", "tier": "backend"}}
+	var first []byte
+	for i := 0; i < 20; i++ {
+		m, err := RenderExternalServices(meta, externalTestConfig())
+		if err != nil {
+			t.Fatalf("RenderExternalServices() error = %v", err)
+		}
+		data, err := m.YAML()
+		if err != nil {
+			t.Fatalf("YAML() error = %v", err)
+		}
+		if first == nil {
+			first = data
+			continue
+		}
+		if !bytes.Equal(data, first) {
+			t.Fatalf("YAML() differs between renders:\n%s\nvs\n%s", first, data)
+		}
+	}
+
+	for _, want := range []string{
+		"kind: ConfigMap\n",
+		"---\napiVersion: v1\nkind: Secret\n",
+		"type: Opaque\n",
+		"  DATABASE_HOST: db.example.com\n",
+		"  DATABASE_PASSWORD: pg-s3cret\n",
+	} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("YAML() = %s, want it to contain %q", first, want)
+		}
+	}
+	if strings.Contains(string(first), "REDIS_PASSWORD") {
+		t.Errorf("YAML() = %s, want the referenced REDIS_PASSWORD left to Env", first)
+	}
+}
+
+func TestRenderExternalServicesCopiesLabels(t *testing.T) {
+	meta := ObjectMeta{Name: "portal-external", Labels: map[string]string{"app": "portal"}}
+	m, err := RenderExternalServices(meta, externalTestConfig())
+	if err != nil {
+		t.Fatalf("RenderExternalServices() error = %v", err)
+	}
+
+	// Labeling one manifest changes neither the other nor the caller's map
+	m.Secret.Metadata.Labels["kind"] = "secret"
+	if _, ok := m.ConfigMap.Metadata.Labels["kind"]; ok {
+		t.Errorf("ConfigMap labels = %v, want them not shared with the Secret", m.ConfigMap.Metadata.Labels)
+	}
+	if _, ok := meta.Labels["kind"]; ok {
+		t.Errorf("caller labels = %v, want them unchanged", meta.Labels)
+	}
+}
+
+func TestRenderExternalServicesValidates(t *testing.T) {
+	config := externalTestConfig()
+	config.Database.Port = 0
+	if _, err := RenderExternalServices(ObjectMeta{Name: "portal"}, config); err == nil || !strings.Contains(err.Error(), "Database.Port") {
+		t.Errorf("RenderExternalServices() error = %v, want Database.Port to be required", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "host", want: "HOST"},
+		{key: "poolSize", want: "POOL_SIZE"},
+		{key: "oauthClientId", want: "OAUTH_CLIENT_ID"},
+		{key: "openTelemetry", want: "OPEN_TELEMETRY"},
+		{key: "sessionTtl", want: "SESSION_TTL"},
+		{key: "caFile", want: "CA_FILE"},
+		{key: "tlsCAFile", want: "TLS_CA_FILE"},
+		{key: "db", want: "DB"},
+	}
+
+	for _, tt := range tests {
+		if got := envName(tt.key); got != tt.want {
+			t.Errorf("envName(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package helmcharts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeValues merges overlays onto base with Helm's coalescing rules, decodes
+// the result into a new T and validates it, so the values checked are exactly
+// the ones Helm renders with. Unknown keys are reported per document, and every
+// issue is located in the last document that sets its path.
+func MergeValues[T any, PT interface {
+	*T
+	Validatable
+}](base *Document, overlays ...*Document) (*T, *ValidationReport, error) {
+	v := new(T)
+	report, err := validateDocuments(PT(v), append([]*Document{base}, overlays...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, report, nil
+}
+
+// validateDocuments strictly decodes each of docs, then merges them, resolves
+// template references, decodes the result into v and validates it with its
+// Validate method and checks
+func validateDocuments(v Validatable, docs []*Document, checks ...Check) (*ValidationReport, error) {
+	report := &ValidationReport{}
+	for _, doc := range docs {
+		// Decode each document on its own so that type errors name their file
+		scratch := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		r, err := doc.DecodeStrict(scratch)
+		if err != nil {
+			return nil, err
+		}
+		report.Issues = append(report.Issues, r.Issues...)
+	}
+
+	merged, err := MergeDocuments(docs[0], docs[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	templates := resolveTemplates(merged, v, docs)
+	if err := merged.Decode(v); err != nil {
+		return nil, err
+	}
+	report.Issues = append(report.Issues, templates.Issues...)
+	var errs Errors
+	errs.Nested("", v.Validate())
+	for _, check := range checks {
+		errs.Nested("", check(v))
+	}
+	for _, issue := range NewValidationReport(v, errs.Err(), docs...).Issues {
+		// Unresolved templates already explain why the value is invalid
+		if !templates.hasIssue(issue.Path) {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+	return report, nil
+}
+
+// MergeDocuments coalesces overlays onto base the way Helm combines values
+// files: mappings are merged key by key, any other value (lists included)
+// replaces the value it overrides and null deletes the key. Documents returned
+// by ParseSet are applied the way Helm applies --set flags instead. The
+// documents are left untouched; the merged nodes keep the positions of the
+// nodes they were copied from. A --set flag that does not fit the values merged
+// before it, such as a.b=1 when a is a string, is an error naming the flag.
+func MergeDocuments(base *Document, overlays ...*Document) (*Document, error) {
+	var root *yaml.Node
+	var files []string
+	for _, doc := range append([]*Document{base}, overlays...) {
+		if doc == nil {
+			continue
+		}
+		files = append(files, doc.File)
+		if len(doc.sets) > 0 {
+			// --set flags update the values merged so far in place
+			applied := &Document{}
+			if root != nil {
+				applied.Root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+			}
+			for _, e := range doc.sets {
+				if err := applied.applySet(e); err != nil {
+					return nil, fmt.Errorf("--%s %q: %w", e.kind, e.expr, err)
+				}
+			}
+			root = applied.Root.Content[0]
+			continue
+		}
+		if doc.Root == nil || len(doc.Root.Content) == 0 || isNullNode(doc.Root.Content[0]) {
+			continue
+		}
+		root = coalesceNode(root, doc.Root.Content[0])
+	}
+
+	merged := &Document{File: strings.Join(files, ", ")}
+	if root != nil {
+		merged.Root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	}
+	return merged, nil
+}
+
+// coalesceNode merges src onto dst, which is owned by the merge, and returns the result
+func coalesceNode(dst, src *yaml.Node) *yaml.Node {
+	src = resolveAlias(src)
+	if dst == nil || dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		return copyNode(src)
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		idx := mappingIndex(dst, key.Value)
+		switch {
+		case isNullNode(value):
+			if idx >= 0 {
+				dst.Content = append(dst.Content[:idx], dst.Content[idx+2:]...)
+			}
+		case idx >= 0:
+			dst.Content[idx+1] = coalesceNode(dst.Content[idx+1], value)
+		default:
+			dst.Content = append(dst.Content, copyNode(key), copyNode(value))
+		}
+	}
+	return dst
+}
+
+// copyNode deeply copies n, replacing aliases with copies of their anchors
+func copyNode(n *yaml.Node) *yaml.Node {
+	n = resolveAlias(n)
+	out := *n
+	out.Anchor = ""
+	if n.Content != nil {
+		out.Content = make([]*yaml.Node, len(n.Content))
+		for i, c := range n.Content {
+			out.Content[i] = copyNode(c)
+		}
+	}
+	return &out
+}
+
+// mappingIndex returns the index of the key node of key in a mapping node, or -1
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func isNullNode(n *yaml.Node) bool {
+	n = resolveAlias(n)
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
@@ -0,0 +1,168 @@
+package helmcharts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func mustParseDocument(t *testing.T, file, data string) *Document {
+	t.Helper()
+	doc, err := ParseDocument(file, []byte(data))
+	if err != nil {
+		t.Fatalf("ParseDocument(%s) error = %v", file, err)
+	}
+	return doc
+}
+
+func TestMergeDocuments(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", `defaults: &defaults
+  type: ClusterIP
+service:
+  <<: *defaults
+  port: 80
+  annotations:
+    team: web
+    tier: frontend
+ingress:
+  enabled: false
+  hosts:
+    - host: a.example.com
+    - host: b.example.com
+`)
+	stage := mustParseDocument(t, "stage.yaml", `service:
+  port: 8080
+  annotations:
+    tier: null
+    stage: prod
+ingress:
+  hosts:
+    - host: prod.example.com
+`)
+	app := mustParseDocument(t, "app.yaml", `ingress:
+  enabled: true
+defaults: ~
+`)
+
+	merged, err := MergeDocuments(base, stage, app)
+	if err != nil {
+		t.Fatalf("MergeDocuments() error = %v", err)
+	}
+	if merged.File != "values.yaml, stage.yaml, app.yaml" {
+		t.Errorf("File = %q", merged.File)
+	}
+
+	var got map[string]any
+	if err := merged.Decode(&got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	var want map[string]any
+	if err := yaml.Unmarshal([]byte(`service:
+  type: ClusterIP
+  port: 8080
+  annotations:
+    team: web
+    stage: prod
+ingress:
+  enabled: true
+  hosts:
+    - host: prod.example.com
+`), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	gotYAML, _ := yaml.Marshal(got)
+	wantYAML, _ := yaml.Marshal(want)
+	if string(gotYAML) != string(wantYAML) {
+		t.Errorf("merged values =\n%s\nwant\n%s", gotYAML, wantYAML)
+	}
+
+	// The inputs are not modified by the merge
+	var original map[string]any
+	if err := base.Decode(&original); err != nil {
+		t.Fatal(err)
+	}
+	if original["service"].(map[string]any)["port"] != 80 {
+		t.Errorf("base document was modified: %v", original)
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", `service:
+  type: ClusterIP
+  port: 80
+ingress:
+  enabled: false
+  hosts:
+    - host: app.example.com
+      paths: [{path: /, pathType: Prefix}]
+`)
+	overlay := mustParseDocument(t, "stage.yaml", `service:
+  port: 0
+  protocl: TCP
+ingress:
+  enabled: true
+  className: nginx
+`)
+
+	values, report, err := MergeValues[reportTestValues](base, overlay)
+	if err != nil {
+		t.Fatalf("MergeValues() error = %v", err)
+	}
+	if values.Service.Type != "ClusterIP" || !values.Ingress.Enabled || len(values.Ingress.Hosts) != 1 {
+		t.Errorf("merged values = %+v", values)
+	}
+
+	want := []ValidationIssue{
+		{Path: "service.protocl", File: "stage.yaml", Line: 3, Tag: "unknown_field"},
+		{Path: "service.port", File: "stage.yaml", Line: 2, Tag: "min"},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d:\n%s", len(report.Issues), len(want), report)
+	}
+	for i, w := range want {
+		got := report.Issues[i]
+		if got.Path != w.Path || got.File != w.File || got.Line != w.Line || got.Tag != w.Tag {
+			t.Errorf("issue %d = %s (%s), want %s:%d: %s (%s)", i, got, got.Tag, w.File, w.Line, w.Path, w.Tag)
+		}
+	}
+}
+
+func TestMergeValuesReportsTypeErrorsPerFile(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", "service:\n  port: 80\n")
+	overlay := mustParseDocument(t, "stage.yaml", "service:\n  port: eighty\n")
+
+	_, _, err := MergeValues[reportTestValues](base, overlay)
+	if err == nil {
+		t.Fatal("MergeValues() should fail to decode a string port")
+	}
+	if got := err.Error(); !strings.HasPrefix(got, "stage.yaml:") {
+		t.Errorf("error = %q, want it to name stage.yaml", got)
+	}
+}
+
+func TestLoadValuesWithOverlays(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "values.yaml")
+	overlayPath := filepath.Join(dir, "prod.yaml")
+	if err := os.WriteFile(basePath, []byte("service:\n  type: ClusterIP\n  port: 80\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlayPath, []byte("service:\n  type: LoadBalancer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, report, err := LoadValues[reportTestValues](basePath, overlayPath)
+	if err != nil {
+		t.Fatalf("LoadValues() error = %v", err)
+	}
+	if !report.Valid() {
+		t.Errorf("LoadValues() issues:\n%s", report)
+	}
+	if values.Service.Type != "LoadBalancer" || values.Service.Port != 80 {
+		t.Errorf("Service = %+v, want LoadBalancer on port 80", values.Service)
+	}
+}
@@ -0,0 +1,218 @@
+package helmcharts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOIDCDiscoveryTTL is how long OIDCDiscoverer caches a discovery
+// document when its TTL is not set
+const DefaultOIDCDiscoveryTTL = time.Hour
+
+// maxOIDCDiscoverySize bounds the discovery documents read from issuers
+const maxOIDCDiscoverySize = 1 << 20
+
+// OIDCDiscovery represents the parts of an OpenID Connect discovery document
+// (/.well-known/openid-configuration) that AuthConfig uses
+type OIDCDiscovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	ScopesSupported       []string `json:"scopes_supported,omitempty"`
+}
+
+// OIDCDiscoverer fetches and caches the discovery documents of OIDC issuers
+type OIDCDiscoverer struct {
+	// Client fetches the documents, http.DefaultClient when nil
+	Client *http.Client
+
+	// TTL is how long a fetched document is reused, DefaultOIDCDiscoveryTTL
+	// when zero
+	TTL time.Duration
+
+	// OfflineFile, when set, is a discovery document read from disk instead
+	// of fetching one, for air-gapped clusters and CI
+	OfflineFile string
+
+	// now returns the current time, time.Now when nil
+	now func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedOIDCDiscovery
+}
+
+type cachedOIDCDiscovery struct {
+	doc     *OIDCDiscovery
+	expires time.Time
+}
+
+// Discover returns the discovery document of issuer, from the cache when it
+// was fetched within the TTL. The document must name issuer as its issuer, as
+// OpenID Connect Discovery requires, and have the authorization, token and
+// JWKS endpoints. The cache is not locked while fetching, so a slow issuer
+// does not hold up the others.
+func (d *OIDCDiscoverer) Discover(ctx context.Context, issuer string) (*OIDCDiscovery, error) {
+	if d.OfflineFile != "" {
+		data, err := os.ReadFile(d.OfflineFile)
+		if err != nil {
+			return nil, err
+		}
+		return parseOIDCDiscovery(data, issuer, d.OfflineFile)
+	}
+
+	now := time.Now
+	if d.now != nil {
+		now = d.now
+	}
+	if doc, ok := d.cached(issuer, now()); ok {
+		return doc, nil
+	}
+
+	doc, err := d.fetch(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	ttl := d.TTL
+	if ttl == 0 {
+		ttl = DefaultOIDCDiscoveryTTL
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	// a concurrent call may have fetched the document meanwhile; keep its
+	// copy so that every caller gets the same one
+	if cached, ok := d.cache[issuer]; ok && now().Before(cached.expires) {
+		return cached.doc, nil
+	}
+	if d.cache == nil {
+		d.cache = map[string]cachedOIDCDiscovery{}
+	}
+	d.cache[issuer] = cachedOIDCDiscovery{doc: doc, expires: now().Add(ttl)}
+	return doc, nil
+}
+
+// cached returns the discovery document of issuer when it has not expired at now
+func (d *OIDCDiscoverer) cached(issuer string, now time.Time) (*OIDCDiscovery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cached, ok := d.cache[issuer]
+	if !ok || !now.Before(cached.expires) {
+		return nil, false
+	}
+	return cached.doc, true
+}
+
+// fetch downloads the discovery document of issuer
+func (d *OIDCDiscoverer) fetch(ctx context.Context, issuer string) (*OIDCDiscovery, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOIDCDiscoverySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxOIDCDiscoverySize {
+		return nil, fmt.Errorf("%s returned more than %d bytes", url, maxOIDCDiscoverySize)
+	}
+	return parseOIDCDiscovery(data, issuer, url)
+}
+
+// parseOIDCDiscovery decodes and checks the discovery document of issuer read from source
+func parseOIDCDiscovery(data []byte, issuer, source string) (*OIDCDiscovery, error) {
+	var doc OIDCDiscovery
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s is not a discovery document: %v", source, err)
+	}
+	if doc.Issuer != issuer {
+		return nil, fmt.Errorf("%s is the discovery document of issuer %q, not %q", source, doc.Issuer, issuer)
+	}
+	for _, f := range []struct{ name, value string }{
+		{"authorization_endpoint", doc.AuthorizationEndpoint},
+		{"token_endpoint", doc.TokenEndpoint},
+		{"jwks_uri", doc.JWKSURI},
+	} {
+		if f.value == "" {
+			return nil, fmt.Errorf("%s has no %s", source, f.name)
+		}
+	}
+	return &doc, nil
+}
+
+// Discover reads the discovery document of OIDCIssuer with d and applies it
+// with ApplyOIDCDiscovery. It does nothing when OIDCIssuer is not set.
+func (a *AuthConfig) Discover(ctx context.Context, d *OIDCDiscoverer) error {
+	if a.OIDCIssuer == "" {
+		return nil
+	}
+	doc, err := d.Discover(ctx, a.OIDCIssuer)
+	if err != nil {
+		var errs Errors
+		errs.Add("OIDCIssuer", "oidc_discovery", fmt.Sprintf("discovery failed: %v", err))
+		return errs
+	}
+	return a.ApplyOIDCDiscovery(doc)
+}
+
+// ApplyOIDCDiscovery fills in the endpoints that are not set from doc and
+// cross-checks those that are, so that a hand-copied URL cannot silently
+// drift from the issuer. The scopes must be supported by the issuer when it
+// lists the scopes it supports. Nothing is filled in when a check fails.
+func (a *AuthConfig) ApplyOIDCDiscovery(doc *OIDCDiscovery) error {
+	var errs Errors
+	var fill []func()
+	for _, f := range []struct {
+		field, name string
+		value       *string
+		discovered  string
+	}{
+		{"OAuthAuthURL", "authorization_endpoint", &a.OAuthAuthURL, doc.AuthorizationEndpoint},
+		{"OAuthTokenURL", "token_endpoint", &a.OAuthTokenURL, doc.TokenEndpoint},
+		{"OAuthJWKSURL", "jwks_uri", &a.OAuthJWKSURL, doc.JWKSURI},
+	} {
+		switch *f.value {
+		case "":
+			fill = append(fill, func() { *f.value = f.discovered })
+		case f.discovered:
+		default:
+			errs.Add(f.field, "oidc_discovery", fmt.Sprintf("does not match the %s %s of the issuer (got %q)", f.name, f.discovered, *f.value))
+		}
+	}
+	if len(doc.ScopesSupported) > 0 {
+		for i, scope := range a.OAuthScopes {
+			if !slices.Contains(doc.ScopesSupported, scope) {
+				errs.Add(fmt.Sprintf("OAuthScopes[%d]", i), "oidc_discovery", fmt.Sprintf("is not supported by the issuer, which supports %s (got %q)", strings.Join(doc.ScopesSupported, ", "), scope))
+			}
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+	for _, f := range fill {
+		f()
+	}
+	return nil
+}
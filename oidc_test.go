@@ -0,0 +1,271 @@
+package helmcharts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newOIDCTestProvider starts a stand-in OIDC provider serving its discovery
+// document and counts the requests for it
+func newOIDCTestProvider(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(oidcTestDocument(srv.URL))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func oidcTestDocument(issuer string) OIDCDiscovery {
+	return OIDCDiscovery{
+		Issuer:                issuer,
+		AuthorizationEndpoint: issuer + "/authorize",
+		TokenEndpoint:         issuer + "/oauth/token",
+		JWKSURI:               issuer + "/.well-known/jwks.json",
+		ScopesSupported:       []string{"openid", "profile", "email"},
+	}
+}
+
+func TestAuthConfigDiscover(t *testing.T) {
+	srv, _ := newOIDCTestProvider(t)
+
+	tests := []struct {
+		name   string
+		config AuthConfig
+		want   []string
+	}{
+		{
+			name:   "populates the endpoints",
+			config: AuthConfig{OIDCIssuer: srv.URL, OAuthScopes: []string{"openid", "email"}},
+		},
+		{
+			name:   "matching endpoints",
+			config: AuthConfig{OIDCIssuer: srv.URL, OAuthAuthURL: srv.URL + "/authorize", OAuthTokenURL: srv.URL + "/oauth/token"},
+		},
+		{
+			name:   "drifted endpoint",
+			config: AuthConfig{OIDCIssuer: srv.URL, OAuthTokenURL: srv.URL + "/token"},
+			want:   []string{"OAuthTokenURL: does not match the token_endpoint " + srv.URL + "/oauth/token of the issuer"},
+		},
+		{
+			name:   "unsupported scope",
+			config: AuthConfig{OIDCIssuer: srv.URL, OAuthScopes: []string{"openid", "offline_access"}},
+			want:   []string{`OAuthScopes[1]: is not supported by the issuer, which supports openid, profile, email (got "offline_access")`},
+		},
+		{
+			name:   "issuer mismatch",
+			config: AuthConfig{OIDCIssuer: srv.URL + "/"},
+			want:   []string{"OIDCIssuer: discovery failed", `is the discovery document of issuer "` + srv.URL + `"`},
+		},
+		{
+			name:   "unknown issuer",
+			config: AuthConfig{OIDCIssuer: srv.URL + "/tenant"},
+			want:   []string{"OIDCIssuer: discovery failed", "404 Not Found"},
+		},
+		{
+			name:   "no issuer",
+			config: AuthConfig{OAuthAuthURL: "https://auth.example.com/authorize"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &OIDCDiscoverer{Client: srv.Client()}
+			err := tt.config.Discover(context.Background(), d)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Discover() error = %v", err)
+				}
+				if tt.config.OIDCIssuer != "" && (tt.config.OAuthAuthURL != srv.URL+"/authorize" ||
+					tt.config.OAuthTokenURL != srv.URL+"/oauth/token" || tt.config.OAuthJWKSURL != srv.URL+"/.well-known/jwks.json") {
+					t.Errorf("Discover() endpoints = %s, %s, %s", tt.config.OAuthAuthURL, tt.config.OAuthTokenURL, tt.config.OAuthJWKSURL)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Discover() error = nil, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Discover() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOIDCDiscovererCaches(t *testing.T) {
+	srv, hits := newOIDCTestProvider(t)
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	d := &OIDCDiscoverer{Client: srv.Client(), TTL: time.Minute, now: func() time.Time { return now }}
+
+	for i := 0; i < 3; i++ {
+		if _, err := d.Discover(context.Background(), srv.URL); err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("provider hits = %d, want 1 within the TTL", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := d.Discover(context.Background(), srv.URL); err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("provider hits = %d, want 2 after the TTL", got)
+	}
+}
+
+func TestOIDCDiscovererFetchesConcurrently(t *testing.T) {
+	fast, _ := newOIDCTestProvider(t)
+	fetching, release := make(chan struct{}), make(chan struct{})
+	var slow *httptest.Server
+	slow = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(fetching)
+		<-release
+		_ = json.NewEncoder(w).Encode(oidcTestDocument(slow.URL))
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+
+	d := &OIDCDiscoverer{}
+	if _, err := d.Discover(context.Background(), fast.URL); err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	slowErr := make(chan error, 1)
+	go func() {
+		_, err := d.Discover(context.Background(), slow.URL)
+		slowErr <- err
+	}()
+	<-fetching
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := d.Discover(context.Background(), fast.URL)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Discover() of a cached issuer waited for the fetch of another one")
+	}
+
+	close(release)
+	if err := <-slowErr; err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+}
+
+func TestApplyOIDCDiscoveryFailure(t *testing.T) {
+	doc := oidcTestDocument("https://auth.tacokumo.dev")
+	config := AuthConfig{
+		OIDCIssuer:    doc.Issuer,
+		OAuthTokenURL: doc.Issuer + "/token",
+		OAuthScopes:   []string{"openid", "offline_access"},
+	}
+	want := config
+	if err := config.ApplyOIDCDiscovery(&doc); err == nil {
+		t.Fatal("ApplyOIDCDiscovery() error = nil, want the drifted token endpoint")
+	}
+	if config.OAuthAuthURL != want.OAuthAuthURL || config.OAuthTokenURL != want.OAuthTokenURL || config.OAuthJWKSURL != want.OAuthJWKSURL {
+		t.Errorf("ApplyOIDCDiscovery() endpoints = %s, %s, %s, want them unchanged on failure", config.OAuthAuthURL, config.OAuthTokenURL, config.OAuthJWKSURL)
+	}
+}
+
+func TestOIDCDiscovererOffline(t *testing.T) {
+	dir := t.TempDir()
+	issuer := "https://auth.tacokumo.dev"
+	data, err := json.Marshal(oidcTestDocument(issuer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "openid-configuration.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := AuthConfig{OIDCIssuer: issuer}
+	d := &OIDCDiscoverer{OfflineFile: file, Client: &http.Client{Transport: failingTransport{t}}}
+	if err := config.Discover(context.Background(), d); err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if config.OAuthJWKSURL != issuer+"/.well-known/jwks.json" {
+		t.Errorf("OAuthJWKSURL = %q, want the one of the offline document", config.OAuthJWKSURL)
+	}
+
+	other := AuthConfig{OIDCIssuer: "https://login.example.com"}
+	if err := other.Discover(context.Background(), d); err == nil || !strings.Contains(err.Error(), `is the discovery document of issuer "https://auth.tacokumo.dev"`) {
+		t.Errorf("Discover() error = %v, want the issuer mismatch", err)
+	}
+
+	incomplete := filepath.Join(dir, "incomplete.json")
+	if err := os.WriteFile(incomplete, []byte(`{"issuer": "https://auth.tacokumo.dev", "authorization_endpoint": "https://auth.tacokumo.dev/authorize"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d.OfflineFile = incomplete
+	if _, err := d.Discover(context.Background(), issuer); err == nil || !strings.Contains(err.Error(), "has no token_endpoint") {
+		t.Errorf("Discover() error = %v, want the missing token_endpoint", err)
+	}
+}
+
+// failingTransport fails the test when a request is made
+type failingTransport struct{ t *testing.T }
+
+func (f failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.t.Errorf("unexpected request to %s in offline mode", r.URL)
+	return nil, http.ErrHandlerTimeout
+}
+
+func TestAuthConfigValidateWithIssuer(t *testing.T) {
+	base := AuthConfig{
+		Enabled:           true,
+		OAuthClientID:     "portal",
+		OAuthClientSecret: SecretValue{SecretKeyRef: &SecretKeyRef{Name: "oauth", Key: "secret"}},
+		OAuthCallbackURL:  "https://portal.tacokumo.dev/callback",
+		SessionSecret:     SecretValue{SecretKeyRef: &SecretKeyRef{Name: "session", Key: "secret"}},
+		SessionName:       "portal",
+		SessionTTL:        "24h",
+	}
+
+	withIssuer := base
+	withIssuer.OIDCIssuer = "https://auth.tacokumo.dev"
+	if err := withIssuer.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want the endpoints to be optional with an issuer", err)
+	}
+
+	err := base.Validate()
+	for _, want := range []string{
+		"OAuthAuthURL: is required when enabled is true, unless oidcIssuer is set",
+		"OAuthTokenURL: is required when enabled is true, unless oidcIssuer is set",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
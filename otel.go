@@ -0,0 +1,126 @@
+package helmcharts
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sampler of traces when OpenTelemetryConfig.TracingSampler is not set
+const defaultTracesSampler = "parentbased_traceidratio"
+
+// EnvVars returns the environment variables of the OpenTelemetry SDK
+// specification that configure the SDK as described by the config, in a fixed
+// order so that rendered pod specs are stable:
+//
+//	OTEL_SDK_DISABLED                    true when the config is not enabled, and then the only variable
+//	OTEL_SERVICE_NAME                    ServiceName
+//	OTEL_RESOURCE_ATTRIBUTES             ResourceAttributes and service.version=ServiceVersion
+//	OTEL_TRACES_EXPORTER                 otlp, or none when tracing is disabled
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT   TracingEndpoint
+//	OTEL_EXPORTER_OTLP_TRACES_PROTOCOL   TracingProtocol
+//	OTEL_TRACES_SAMPLER                  TracingSampler, parentbased_traceidratio by default
+//	OTEL_TRACES_SAMPLER_ARG              TracingSampling when set, for the ratio based samplers
+//	OTEL_METRICS_EXPORTER                otlp, or none when metrics are disabled
+//	OTEL_EXPORTER_OTLP_METRICS_ENDPOINT  MetricsEndpoint
+//	OTEL_EXPORTER_OTLP_METRICS_PROTOCOL  MetricsProtocol
+//	OTEL_METRIC_EXPORT_INTERVAL          MetricsInterval in milliseconds
+//	OTEL_LOGS_EXPORTER                   otlp, or none when logging is disabled
+//	OTEL_EXPORTER_OTLP_HEADERS           Headers
+//
+// Lists are written as comma separated key=value pairs sorted by key, with the
+// values percent-encoded as the specification requires. Variables of unset
+// fields are left out, so the SDK defaults apply. Headers usually hold
+// credentials; charts that must not render them into the pod spec can drop
+// OTEL_EXPORTER_OTLP_HEADERS and load it from a Secret instead. LogLevel and
+// LogFormat configure the application's own logger and are not mapped.
+func (o *OpenTelemetryConfig) EnvVars() []EnvVar {
+	if !o.Enabled {
+		return []EnvVar{{Name: "OTEL_SDK_DISABLED", Value: "true"}}
+	}
+
+	var env []EnvVar
+	add := func(name, value string) {
+		if value != "" {
+			env = append(env, EnvVar{Name: name, Value: value})
+		}
+	}
+
+	attributes := maps.Clone(o.ResourceAttributes)
+	if o.ServiceVersion != "" {
+		if attributes == nil {
+			attributes = map[string]string{}
+		}
+		attributes["service.version"] = o.ServiceVersion
+	}
+	add("OTEL_SERVICE_NAME", o.ServiceName)
+	add("OTEL_RESOURCE_ATTRIBUTES", encodeOTELList(attributes))
+
+	if o.TracingEnabled {
+		sampler := o.TracingSampler
+		if sampler == "" {
+			sampler = defaultTracesSampler
+		}
+		add("OTEL_TRACES_EXPORTER", "otlp")
+		add("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", o.TracingEndpoint)
+		add("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", o.TracingProtocol)
+		add("OTEL_TRACES_SAMPLER", sampler)
+		if strings.HasSuffix(sampler, "traceidratio") && o.TracingSampling != nil {
+			add("OTEL_TRACES_SAMPLER_ARG", strconv.FormatFloat(*o.TracingSampling, 'g', -1, 64))
+		}
+	} else {
+		add("OTEL_TRACES_EXPORTER", "none")
+	}
+
+	if o.MetricsEnabled {
+		add("OTEL_METRICS_EXPORTER", "otlp")
+		add("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", o.MetricsEndpoint)
+		add("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", o.MetricsProtocol)
+		if interval, err := time.ParseDuration(o.MetricsInterval); err == nil && interval > 0 {
+			add("OTEL_METRIC_EXPORT_INTERVAL", strconv.FormatInt(interval.Milliseconds(), 10))
+		}
+	} else {
+		add("OTEL_METRICS_EXPORTER", "none")
+	}
+
+	if o.LoggingEnabled {
+		add("OTEL_LOGS_EXPORTER", "otlp")
+	} else {
+		add("OTEL_LOGS_EXPORTER", "none")
+	}
+
+	add("OTEL_EXPORTER_OTLP_HEADERS", encodeOTELList(o.Headers))
+	return env
+}
+
+// encodeOTELList encodes m as the comma separated key=value list of
+// OTEL_RESOURCE_ATTRIBUTES and OTEL_EXPORTER_OTLP_HEADERS, sorted by key.
+// Keys are HTTP tokens, as validated, and values are percent-encoded.
+func encodeOTELList(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		pairs = append(pairs, k+"="+percentEncodeOTEL(m[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// percentEncodeOTEL percent-encodes every byte of s except letters, digits
+// and -._~:/, so that the value cannot break the list and is decoded the same
+// by every SDK
+func percentEncodeOTEL(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("-._~:/", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
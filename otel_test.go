@@ -0,0 +1,192 @@
+package helmcharts
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenTelemetryConfigEnvVars(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OpenTelemetryConfig
+		want   []EnvVar
+	}{
+		{
+			name:   "disabled",
+			config: OpenTelemetryConfig{ServiceName: "portal", TracingEnabled: true, TracingEndpoint: "http://otel:4318"},
+			want:   []EnvVar{{Name: "OTEL_SDK_DISABLED", Value: "true"}},
+		},
+		{
+			name:   "no signals",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal"},
+			want: []EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "portal"},
+				{Name: "OTEL_TRACES_EXPORTER", Value: "none"},
+				{Name: "OTEL_METRICS_EXPORTER", Value: "none"},
+				{Name: "OTEL_LOGS_EXPORTER", Value: "none"},
+			},
+		},
+		{
+			name: "all signals",
+			config: OpenTelemetryConfig{
+				Enabled:            true,
+				ServiceName:        "portal",
+				ServiceVersion:     "1.2.0",
+				TracingEnabled:     true,
+				TracingEndpoint:    "http://otel-collector:4318/v1/traces",
+				TracingProtocol:    "http/protobuf",
+				TracingSampling:    float64Ptr(0.1),
+				MetricsEnabled:     true,
+				MetricsEndpoint:    "http://otel-collector:4317",
+				MetricsProtocol:    "grpc",
+				MetricsInterval:    "30s",
+				LoggingEnabled:     true,
+				ResourceAttributes: map[string]string{"team": "platform", "deployment.environment": "prod"},
+				Headers:            map[string]string{"Authorization": "Bearer abc=", "X-Scope": "a,b"},
+			},
+			want: []EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "portal"},
+				{Name: "OTEL_RESOURCE_ATTRIBUTES", Value: "deployment.environment=prod,service.version=1.2.0,team=platform"},
+				{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+				{Name: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", Value: "http://otel-collector:4318/v1/traces"},
+				{Name: "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", Value: "http/protobuf"},
+				{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+				{Name: "OTEL_TRACES_SAMPLER_ARG", Value: "0.1"},
+				{Name: "OTEL_METRICS_EXPORTER", Value: "otlp"},
+				{Name: "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", Value: "http://otel-collector:4317"},
+				{Name: "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", Value: "grpc"},
+				{Name: "OTEL_METRIC_EXPORT_INTERVAL", Value: "30000"},
+				{Name: "OTEL_LOGS_EXPORTER", Value: "otlp"},
+				{Name: "OTEL_EXPORTER_OTLP_HEADERS", Value: "Authorization=Bearer%20abc%3D,X-Scope=a%2Cb"},
+			},
+		},
+		{
+			name:   "logging only",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal", LoggingEnabled: true},
+			want: []EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "portal"},
+				{Name: "OTEL_TRACES_EXPORTER", Value: "none"},
+				{Name: "OTEL_METRICS_EXPORTER", Value: "none"},
+				{Name: "OTEL_LOGS_EXPORTER", Value: "otlp"},
+			},
+		},
+		{
+			name: "sampler without ratio",
+			config: OpenTelemetryConfig{
+				Enabled: true, ServiceName: "portal",
+				TracingEnabled: true, TracingEndpoint: "http://otel:4317", TracingSampler: "always_on", TracingSampling: float64Ptr(0.5),
+			},
+			want: []EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "portal"},
+				{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+				{Name: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", Value: "http://otel:4317"},
+				{Name: "OTEL_TRACES_SAMPLER", Value: "always_on"},
+				{Name: "OTEL_METRICS_EXPORTER", Value: "none"},
+				{Name: "OTEL_LOGS_EXPORTER", Value: "none"},
+			},
+		},
+		{
+			name: "tracing enabled, sampling unset",
+			config: OpenTelemetryConfig{
+				Enabled: true, ServiceName: "portal",
+				TracingEnabled: true, TracingEndpoint: "http://otel:4317",
+			},
+			want: []EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "portal"},
+				{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+				{Name: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", Value: "http://otel:4317"},
+				{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+				{Name: "OTEL_METRICS_EXPORTER", Value: "none"},
+				{Name: "OTEL_LOGS_EXPORTER", Value: "none"},
+			},
+		},
+		{
+			name: "sampling set to zero",
+			config: OpenTelemetryConfig{
+				Enabled: true, ServiceName: "portal",
+				TracingEnabled: true, TracingEndpoint: "http://otel:4317", TracingSampling: float64Ptr(0),
+			},
+			want: []EnvVar{
+				{Name: "OTEL_SERVICE_NAME", Value: "portal"},
+				{Name: "OTEL_TRACES_EXPORTER", Value: "otlp"},
+				{Name: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", Value: "http://otel:4317"},
+				{Name: "OTEL_TRACES_SAMPLER", Value: "parentbased_traceidratio"},
+				{Name: "OTEL_TRACES_SAMPLER_ARG", Value: "0"},
+				{Name: "OTEL_METRICS_EXPORTER", Value: "none"},
+				{Name: "OTEL_LOGS_EXPORTER", Value: "none"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if got := tt.config.EnvVars(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnvVars() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config OpenTelemetryConfig
+		want   string
+	}{
+		{
+			name:   "unknown protocol",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal", TracingProtocol: "http"},
+			want:   "TracingProtocol",
+		},
+		{
+			name:   "sampling above 1",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal", TracingSampling: float64Ptr(1.5)},
+			want:   "TracingSampling",
+		},
+		{
+			name:   "unknown sampler",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal", TracingSampler: "sometimes"},
+			want:   "TracingSampler",
+		},
+		{
+			name:   "resource attribute key is not a token",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal", ResourceAttributes: map[string]string{"team name": "platform"}},
+			want:   "ResourceAttributes[team name]: must be an HTTP token",
+		},
+		{
+			name:   "header name is not a token",
+			config: OpenTelemetryConfig{Enabled: true, ServiceName: "portal", Headers: map[string]string{"Authorization:": "s3cret"}},
+			want:   "Headers[Authorization:]: must be an HTTP token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentEncodeOTEL(t *testing.T) {
+	for _, s := range []string{"prod", "a,b=c;d e", "100%", "ünïcode", `"quoted"`, "https://example.com/x"} {
+		got := percentEncodeOTEL(s)
+		if strings.ContainsAny(got, ",=; \"") {
+			t.Errorf("percentEncodeOTEL(%q) = %q, want the separators encoded", s, got)
+		}
+		if decoded, err := url.PathUnescape(got); err != nil || decoded != s {
+			t.Errorf("percentEncodeOTEL(%q) = %q, decodes to %q, %v", s, got, decoded, err)
+		}
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,97 @@
+package helmcharts
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAMLPath parses a YAML path as rendered by yamlPathSegments.String,
+// such as main.service.ports[0].port, annotations["a.b/c"] or ports[name=http]
+func parseYAMLPath(path string) yamlPathSegments {
+	var segments yamlPathSegments
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			inner, rest := cutBracket(path[1:])
+			path = rest
+			switch {
+			case strings.HasPrefix(inner, `"`):
+				segments = append(segments, pathSegment{name: unquote(inner), key: true})
+			case strings.HasPrefix(inner, "name="):
+				segments = append(segments, pathSegment{name: unquote(inner[len("name="):]), named: true})
+			default:
+				n, _ := strconv.Atoi(inner)
+				segments = append(segments, pathSegment{index: n, item: true})
+			}
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, pathSegment{name: path[:end]})
+			path = path[end:]
+		}
+	}
+	return segments
+}
+
+// cutBracket splits s after the ] that closes a bracket, skipping quoted text
+func cutBracket(s string) (inner, rest string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == ']' && !quoted:
+			return s[:i], s[i+1:]
+		}
+	}
+	return s, ""
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// LocatePath returns the position of the value at a YAML path such as
+// main.service.ports[0].port in the last of docs that defines it. A path that
+// no document defines is located at its deepest defined parent.
+func LocatePath(path string, docs ...*Document) (file string, line, column int) {
+	var issue ValidationIssue
+	locateIssue(&issue, parseYAMLPath(path), docs)
+	return issue.File, issue.Line, issue.Column
+}
+
+// Comments returns the comments of the document and of each key and value on
+// the way to the value at path, outermost first. Comment markers are kept.
+func (d *Document) Comments(path string) []string {
+	if d.Root == nil {
+		return nil
+	}
+	var comments []string
+	add := func(n *yaml.Node) {
+		if n == nil {
+			return
+		}
+		for _, c := range []string{n.HeadComment, n.LineComment} {
+			if c != "" {
+				comments = append(comments, c)
+			}
+		}
+	}
+	add(d.Root)
+	walkPath(d.Root, parseYAMLPath(path), func(key, value *yaml.Node) {
+		add(key)
+		add(value)
+	})
+	return comments
+}
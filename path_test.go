@@ -0,0 +1,93 @@
+package helmcharts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLPath(t *testing.T) {
+	tests := []string{
+		"main.hpa.maxReplicas",
+		"main.service.ports[0].port",
+		`main.annotations["tacokumo.io/managed-by"]`,
+		`env["a \"quoted\" ]key"].value`,
+		"main.service.ports[name=http].port",
+		`volumes[name="my.volume"]`,
+		"matrix[0][1]",
+	}
+	for _, path := range tests {
+		t.Run(path, func(t *testing.T) {
+			// Paths survive a round trip through their segments
+			if got := parseYAMLPath(path).String(); got != path {
+				t.Errorf("parseYAMLPath(%q).String() = %q", path, got)
+			}
+		})
+	}
+
+	want := yamlPathSegments{{name: "ports"}, {name: "http", named: true}, {name: "port"}}
+	if got := parseYAMLPath("ports[name=http].port"); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseYAMLPath() = %#v, want %#v", got, want)
+	}
+}
+
+func TestLocatePath(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", `main:
+  service:
+    ports:
+      - name: http
+        port: 80
+      - name: https
+        port: 443
+`)
+	overlay := mustParseDocument(t, "prod.yaml", `main:
+  annotations:
+    tacokumo.io/team: web
+`)
+
+	tests := []struct {
+		path string
+		file string
+		line int
+		col  int
+	}{
+		{path: "main.service.ports[1].port", file: "values.yaml", line: 7, col: 9},
+		{path: "main.service.ports[name=https].port", file: "values.yaml", line: 7, col: 9},
+		{path: `main.annotations["tacokumo.io/team"]`, file: "prod.yaml", line: 3, col: 5},
+		// Undefined paths are located at their deepest defined parent
+		{path: "main.service.ports[name=grpc]", file: "values.yaml", line: 3, col: 5},
+		{path: "main.resources.limits", file: "prod.yaml", line: 1, col: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			file, line, col := LocatePath(tt.path, base, overlay)
+			if file != tt.file || line != tt.line || col != tt.col {
+				t.Errorf("LocatePath() = %s:%d:%d, want %s:%d:%d", file, line, col, tt.file, tt.line, tt.col)
+			}
+		})
+	}
+}
+
+func TestDocumentComments(t *testing.T) {
+	doc := mustParseDocument(t, "values.yaml", `# values for the web app
+
+main:
+  # the image is pinned by the release pipeline
+  image: nginx # not latest
+  ports:
+    # public port
+    - name: http
+      port: 80
+`)
+
+	got := doc.Comments("main.ports[0].port")
+	want := []string{"# values for the web app", "# public port"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Comments(ports) = %q, want %q", got, want)
+	}
+
+	got = doc.Comments("main.image")
+	want = []string{"# values for the web app", "# the image is pinned by the release pipeline", "# not latest"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Comments(image) = %q, want %q", got, want)
+	}
+}
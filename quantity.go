@@ -0,0 +1,342 @@
+package helmcharts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// QuantityFormat is the suffix family a Quantity was written in
+type QuantityFormat string
+
+const (
+	// DecimalExponent is e.g. 12e6
+	DecimalExponent QuantityFormat = "DecimalExponent"
+	// BinarySI is e.g. 128Mi
+	BinarySI QuantityFormat = "BinarySI"
+	// DecimalSI is e.g. 500m or 1G
+	DecimalSI QuantityFormat = "DecimalSI"
+)
+
+var (
+	// ErrQuantityFormat is returned when a quantity does not match the Kubernetes grammar
+	ErrQuantityFormat = errors.New("quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'")
+	// ErrQuantitySuffix is returned when the suffix of a quantity is unknown
+	ErrQuantitySuffix = errors.New("unable to parse quantity's suffix")
+)
+
+var (
+	// nano is the smallest amount a Quantity can represent, as in Kubernetes
+	nano = big.NewRat(1, 1_000_000_000)
+	// maxQuantity is the largest magnitude a Quantity can hold
+	maxQuantity = new(big.Rat).SetInt64(math.MaxInt64)
+)
+
+// decimalSuffixes maps decimal SI suffixes to their power of ten
+var decimalSuffixes = map[string]int{
+	"n": -9,
+	"u": -6,
+	"m": -3,
+	"":  0,
+	"k": 3,
+	"M": 6,
+	"G": 9,
+	"T": 12,
+	"P": 15,
+	"E": 18,
+}
+
+// binarySuffixes maps binary SI suffixes to their power of two
+var binarySuffixes = map[string]int{
+	"Ki": 10,
+	"Mi": 20,
+	"Gi": 30,
+	"Ti": 40,
+	"Pi": 50,
+	"Ei": 60,
+}
+
+// Quantity is an exact Kubernetes resource quantity such as 500m or 128Mi.
+// The zero value is a DecimalSI zero.
+type Quantity struct {
+	value  *big.Rat
+	format QuantityFormat
+}
+
+// ParseQuantity parses s following the Kubernetes quantity grammar:
+// a signed decimal number followed by a binary SI suffix (Ki, Mi, ...),
+// a decimal SI suffix (n, u, m, k, M, ...) or a decimal exponent (e3, E-2).
+// As in Kubernetes, values are rounded up to the nearest nano unit.
+func ParseQuantity(s string) (Quantity, error) {
+	negative, number, suffix, err := splitQuantity(s)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if strings.HasSuffix(number, ".") {
+		number += "0"
+	}
+
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return Quantity{}, ErrQuantityFormat
+	}
+	format, err := applySuffix(value, suffix)
+	if err != nil {
+		return Quantity{}, err
+	}
+
+	if value.Cmp(maxQuantity) > 0 {
+		value.Set(maxQuantity)
+	}
+	if negative {
+		value.Neg(value)
+	}
+	return Quantity{value: roundUpToNano(value), format: format}, nil
+}
+
+// splitQuantity splits s into its sign, number and suffix without allocating
+func splitQuantity(s string) (negative bool, number, suffix string, err error) {
+	pos := 0
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		pos++
+	}
+
+	start := pos
+	digits := 0
+	for pos < len(s) && isDigit(s[pos]) {
+		pos++
+		digits++
+	}
+	if pos < len(s) && s[pos] == '.' {
+		pos++
+		for pos < len(s) && isDigit(s[pos]) {
+			pos++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return false, "", "", ErrQuantityFormat
+	}
+	return negative, s[start:pos], s[pos:], nil
+}
+
+// MustParseQuantity is like ParseQuantity but panics on error
+func MustParseQuantity(s string) Quantity {
+	q, err := ParseQuantity(s)
+	if err != nil {
+		panic(fmt.Sprintf("cannot parse %q: %v", s, err))
+	}
+	return q
+}
+
+// applySuffix scales value by suffix and returns the format the suffix belongs to
+func applySuffix(value *big.Rat, suffix string) (QuantityFormat, error) {
+	format, exp, err := parseSuffix(suffix)
+	if err != nil {
+		return "", err
+	}
+	if format == BinarySI {
+		value.Mul(value, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(exp))))
+	} else {
+		value.Mul(value, pow10(exp))
+	}
+	return format, nil
+}
+
+// parseSuffix returns the format of suffix and the power of two (BinarySI) or
+// ten (DecimalSI, DecimalExponent) it stands for
+func parseSuffix(suffix string) (QuantityFormat, int, error) {
+	if exp, ok := decimalSuffixes[suffix]; ok {
+		return DecimalSI, exp, nil
+	}
+	if shift, ok := binarySuffixes[suffix]; ok {
+		return BinarySI, int(shift), nil
+	}
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		exp, err := strconv.Atoi(suffix[1:])
+		// Keep exponents within a range that cannot exhaust memory
+		if err != nil || exp < -1000 || exp > 1000 {
+			return "", 0, ErrQuantitySuffix
+		}
+		return DecimalExponent, exp, nil
+	}
+	return "", 0, ErrQuantitySuffix
+}
+
+// validQuantity reports whether s is a non-negative quantity, checking the
+// grammar only so that validation does not allocate
+func validQuantity(s string) bool {
+	negative, number, suffix, err := splitQuantity(s)
+	if err != nil {
+		return false
+	}
+	if _, _, err := parseSuffix(suffix); err != nil {
+		return false
+	}
+	return !negative || strings.Trim(number, "0.") == ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// pow10 returns 10^exp as a rational
+func pow10(exp int) *big.Rat {
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), p)
+	}
+	return new(big.Rat).SetInt(p)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// roundUpToNano rounds v away from zero to a multiple of one nano unit
+func roundUpToNano(v *big.Rat) *big.Rat {
+	scaled := new(big.Rat).Quo(v, nano)
+	if scaled.IsInt() {
+		return v
+	}
+	n := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	if v.Sign() > 0 {
+		n.Add(n, big.NewInt(1))
+	} else {
+		n.Sub(n, big.NewInt(1))
+	}
+	return new(big.Rat).Mul(new(big.Rat).SetInt(n), nano)
+}
+
+func (q Quantity) rat() *big.Rat {
+	if q.value == nil {
+		return new(big.Rat)
+	}
+	return q.value
+}
+
+// Format returns the suffix family the quantity was written in
+func (q Quantity) Format() QuantityFormat {
+	if q.format == "" {
+		return DecimalSI
+	}
+	return q.format
+}
+
+// Cmp compares q and other, returning -1, 0 or +1
+func (q Quantity) Cmp(other Quantity) int {
+	return q.rat().Cmp(other.rat())
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of q
+func (q Quantity) Sign() int {
+	return q.rat().Sign()
+}
+
+// IsZero reports whether q is zero
+func (q Quantity) IsZero() bool {
+	return q.Sign() == 0
+}
+
+// Add returns q + other, keeping the format of q
+func (q Quantity) Add(other Quantity) Quantity {
+	return Quantity{value: new(big.Rat).Add(q.rat(), other.rat()), format: q.format}
+}
+
+// Sub returns q - other, keeping the format of q
+func (q Quantity) Sub(other Quantity) Quantity {
+	return Quantity{value: new(big.Rat).Sub(q.rat(), other.rat()), format: q.format}
+}
+
+// Mul returns q multiplied by n, keeping the format of q
+func (q Quantity) Mul(n int64) Quantity {
+	return Quantity{value: new(big.Rat).Mul(q.rat(), new(big.Rat).SetInt64(n)), format: q.format}
+}
+
+// Value returns q rounded up to an integer, as Kubernetes does for memory
+func (q Quantity) Value() int64 {
+	return ceilInt64(q.rat())
+}
+
+// MilliValue returns q in milli-units rounded up, as Kubernetes does for CPU
+func (q Quantity) MilliValue() int64 {
+	return ceilInt64(new(big.Rat).Mul(q.rat(), big.NewRat(1000, 1)))
+}
+
+// ceilInt64 rounds v towards positive infinity, saturating at the int64 range
+func ceilInt64(v *big.Rat) int64 {
+	n := new(big.Int).Quo(v.Num(), v.Denom())
+	if !v.IsInt() && v.Sign() > 0 {
+		n.Add(n, big.NewInt(1))
+	}
+	if !n.IsInt64() {
+		if n.Sign() > 0 {
+			return math.MaxInt64
+		}
+		return math.MinInt64
+	}
+	return n.Int64()
+}
+
+// String returns the canonical form of q: the largest suffix of its format
+// that keeps the number an integer, falling back to DecimalSI
+func (q Quantity) String() string {
+	v := q.rat()
+	if v.Sign() == 0 {
+		return "0"
+	}
+	// Binary amounts below 1Ki are written in decimal to avoid confusion
+	if q.Format() == BinarySI && v.IsInt() && new(big.Rat).Abs(v).Cmp(big.NewRat(1024, 1)) >= 0 {
+		for _, suffix := range []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki"} {
+			unit := new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(binarySuffixes[suffix])))
+			if n := new(big.Rat).Quo(v, unit); n.IsInt() {
+				return n.Num().String() + suffix
+			}
+		}
+		return v.Num().String()
+	}
+	if q.Format() == DecimalExponent {
+		for exp := 18; exp >= -9; exp -= 3 {
+			if n := new(big.Rat).Quo(v, pow10(exp)); n.IsInt() {
+				if exp == 0 {
+					return n.Num().String()
+				}
+				return fmt.Sprintf("%se%d", n.Num().String(), exp)
+			}
+		}
+	}
+	for _, suffix := range []string{"E", "P", "T", "G", "M", "k", "", "m", "u", "n"} {
+		if n := new(big.Rat).Quo(v, pow10(decimalSuffixes[suffix])); n.IsInt() {
+			return n.Num().String() + suffix
+		}
+	}
+	return v.FloatString(9)
+}
+
+// RequestWithinLimit collects a failure on field when the request quantity
+// exceeds the limit quantity. Empty or malformed quantities are left to the
+// resource_quantity validation.
+func (e *Errors) RequestWithinLimit(field, request, limit string) {
+	if request == "" || limit == "" {
+		return
+	}
+	req, err := ParseQuantity(request)
+	if err != nil {
+		return
+	}
+	lim, err := ParseQuantity(limit)
+	if err != nil {
+		return
+	}
+	if req.Cmp(lim) > 0 {
+		e.add(&FieldError{Field: field, Tag: "lte_limit", Param: limit, Value: request, Kind: reflect.String})
+	}
+}
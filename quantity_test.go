@@ -0,0 +1,155 @@
+package helmcharts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantString string
+		wantMilli  int64
+		wantFormat QuantityFormat
+		wantErr    error
+	}{
+		{input: "100m", wantString: "100m", wantMilli: 100, wantFormat: DecimalSI},
+		{input: "0.5", wantString: "500m", wantMilli: 500, wantFormat: DecimalSI},
+		{input: ".5", wantString: "500m", wantMilli: 500, wantFormat: DecimalSI},
+		{input: "1.", wantString: "1", wantMilli: 1000, wantFormat: DecimalSI},
+		{input: "+2", wantString: "2", wantMilli: 2000, wantFormat: DecimalSI},
+		{input: "-1500m", wantString: "-1500m", wantMilli: -1500, wantFormat: DecimalSI},
+		{input: "128Mi", wantString: "128Mi", wantMilli: 128 << 20 * 1000, wantFormat: BinarySI},
+		{input: "1.5Gi", wantString: "1536Mi", wantMilli: 1536 << 20 * 1000, wantFormat: BinarySI},
+		{input: "1024Ki", wantString: "1Mi", wantMilli: 1 << 20 * 1000, wantFormat: BinarySI},
+		{input: "0.5Ki", wantString: "512", wantMilli: 512_000, wantFormat: BinarySI},
+		{input: "1k", wantString: "1k", wantMilli: 1_000_000, wantFormat: DecimalSI},
+		{input: "1G", wantString: "1G", wantMilli: 1_000_000_000_000, wantFormat: DecimalSI},
+		{input: "100u", wantString: "100u", wantMilli: 1, wantFormat: DecimalSI},
+		{input: "1n", wantString: "1n", wantMilli: 1, wantFormat: DecimalSI},
+		{input: "0.1n", wantString: "1n", wantMilli: 1, wantFormat: DecimalSI},
+		{input: "1e3", wantString: "1e3", wantMilli: 1_000_000, wantFormat: DecimalExponent},
+		{input: "12E6", wantString: "12e6", wantMilli: 12_000_000_000, wantFormat: DecimalExponent},
+		{input: "5e-3", wantString: "5e-3", wantMilli: 5, wantFormat: DecimalExponent},
+		{input: "0", wantString: "0", wantMilli: 0, wantFormat: DecimalSI},
+		{input: "", wantErr: ErrQuantityFormat},
+		{input: ".", wantErr: ErrQuantityFormat},
+		{input: "Mi", wantErr: ErrQuantityFormat},
+		{input: "1g", wantErr: ErrQuantitySuffix},
+		{input: "1mi", wantErr: ErrQuantitySuffix},
+		{input: "128MB", wantErr: ErrQuantitySuffix},
+		{input: "1e", wantErr: ErrQuantitySuffix},
+		{input: "1 Gi", wantErr: ErrQuantitySuffix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := ParseQuantity(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseQuantity(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuantity(%q) error = %v", tt.input, err)
+			}
+			if got := q.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+			if got := q.MilliValue(); got != tt.wantMilli {
+				t.Errorf("MilliValue() = %d, want %d", got, tt.wantMilli)
+			}
+			if got := q.Format(); got != tt.wantFormat {
+				t.Errorf("Format() = %s, want %s", got, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestQuantityCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1000m", 0},
+		{"1Gi", "1073741824", 0},
+		{"1Gi", "1G", 1},
+		{"500m", "0.5", 0},
+		{"1e3", "1k", 0},
+		{"100m", "1", -1},
+		{"-1", "0", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := MustParseQuantity(tt.a).Cmp(MustParseQuantity(tt.b)); got != tt.want {
+				t.Errorf("Cmp() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantityArithmetic(t *testing.T) {
+	sum := MustParseQuantity("1Gi").Add(MustParseQuantity("512Mi"))
+	if got := sum.String(); got != "1536Mi" {
+		t.Errorf("1Gi + 512Mi = %s, want 1536Mi", got)
+	}
+
+	diff := MustParseQuantity("1").Sub(MustParseQuantity("250m"))
+	if got := diff.String(); got != "750m" {
+		t.Errorf("1 - 250m = %s, want 750m", got)
+	}
+
+	if got := MustParseQuantity("100m").Mul(3).MilliValue(); got != 300 {
+		t.Errorf("100m * 3 = %dm, want 300m", got)
+	}
+
+	if got := MustParseQuantity("1500m").Value(); got != 2 {
+		t.Errorf("Value() of 1500m = %d, want 2", got)
+	}
+
+	var zero Quantity
+	if !zero.IsZero() || zero.String() != "0" || zero.Add(MustParseQuantity("1k")).String() != "1k" {
+		t.Errorf("zero Quantity is not usable: %s", zero.Add(MustParseQuantity("1k")))
+	}
+}
+
+func TestParseQuantityCapsLargeValues(t *testing.T) {
+	q := MustParseQuantity("100Ei")
+	if got := q.Value(); got != 1<<63-1 {
+		t.Errorf("Value() = %d, want %d", got, int64(1<<63-1))
+	}
+}
+
+func TestRequestWithinLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		limit   string
+		wantErr bool
+	}{
+		{"below limit", "100m", "500m", false},
+		{"equal in other units", "1000m", "1", false},
+		{"above limit", "1Gi", "1G", true},
+		{"no limit", "4", "", false},
+		{"no request", "", "1", false},
+		{"malformed request", "lots", "1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs Errors
+			errs.RequestWithinLimit("Requests.Memory", tt.request, tt.limit)
+			if (len(errs) > 0) != tt.wantErr {
+				t.Fatalf("RequestWithinLimit() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+			if tt.wantErr {
+				want := `Requests.Memory: must not exceed the limit of 1G (got "1Gi")`
+				if got := errs[0].Error(); got != want {
+					t.Errorf("error = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package helmcharts
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces sensitive values wherever values are printed
+const RedactedValue = "***"
+
+var secretValueType = reflect.TypeFor[SecretValue]()
+
+// Redact returns a deep copy of v in which every non-empty string held by a
+// field tagged sensitive:"true", including the items and map values below it,
+// is replaced by RedactedValue. The secretKeyRef of a SecretValue is kept, as
+// it only names where the secret is stored.
+func Redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redactValue(reflect.ValueOf(v), false).Interface()
+}
+
+// MarshalRedactedYAML encodes v as YAML with its sensitive values redacted
+func MarshalRedactedYAML(v any) ([]byte, error) {
+	return yaml.Marshal(Redact(v))
+}
+
+// MarshalRedactedJSON encodes v as JSON with its sensitive values redacted.
+// Fields are named by their YAML keys, as in the values files.
+func MarshalRedactedJSON(v any) ([]byte, error) {
+	data, err := MarshalRedactedYAML(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return json.Marshal(tree)
+}
+
+// redactValue returns a copy of v, redacting its strings when sensitive
+func redactValue(v reflect.Value, sensitive bool) reflect.Value {
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(redactValue(v.Elem(), sensitive))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.New(t).Elem()
+		out.Set(redactValue(v.Elem(), sensitive))
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		if t == secretValueType && sensitive {
+			s := v.Interface().(SecretValue)
+			if s.Value != "" {
+				s.Value = RedactedValue
+			}
+			if s.SecretKeyRef != nil {
+				ref := *s.SecretKeyRef
+				s.SecretKeyRef = &ref
+			}
+			out.Set(reflect.ValueOf(s))
+			return out
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.IsExported() {
+				out.Field(i).Set(redactValue(v.Field(i), sensitive || isSensitive(f)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(redactValue(v.Index(i), sensitive))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(redactValue(v.Index(i), sensitive))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(t)
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), redactValue(iter.Value(), sensitive))
+		}
+		return out
+	case reflect.String:
+		if sensitive && v.Len() > 0 {
+			return reflect.ValueOf(RedactedValue).Convert(t)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// redactAny returns a copy of v with all of its strings redacted
+func redactAny(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redactValue(reflect.ValueOf(v), true).Interface()
+}
+
+// isSensitive reports whether the field is tagged sensitive:"true"
+func isSensitive(f reflect.StructField) bool {
+	return f.Tag.Get("sensitive") == "true"
+}
+
+// sensitivePath reports whether the field at the struct namespace segments of
+// root, or one of the fields holding it, is tagged sensitive:"true"
+func sensitivePath(root reflect.Type, segments []pathSegment) bool {
+	t := root
+	for _, seg := range segments {
+		t = derefType(t)
+		if t == nil {
+			return false
+		}
+		switch {
+		case seg.item:
+			if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+				return false
+			}
+			t = t.Elem()
+		case seg.key:
+			if t.Kind() != reflect.Map {
+				return false
+			}
+			t = t.Elem()
+		default:
+			if t.Kind() != reflect.Struct {
+				return false
+			}
+			f, ok := t.FieldByName(seg.name)
+			if !ok {
+				return false
+			}
+			if isSensitive(f) {
+				return true
+			}
+			t = f.Type
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package helmcharts
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// externalTestConfig returns a valid external services config with literal,
+// referenced and map-valued secrets, shared by the tests built on it
+func externalTestConfig() *ExternalServiceConfig {
+	return &ExternalServiceConfig{
+		Database: PostgreSQLConfig{
+			Host:            "db.example.com",
+			Port:            5432,
+			Database:        "portal",
+			Username:        "portal",
+			Password:        SecretValue{Value: "pg-s3cret"},
+			SSLMode:         "require",
+			ConnMaxLifetime: "30m",
+		},
+		Redis: RedisConfig{
+			Host:     "redis.example.com",
+			Port:     6379,
+			Password: SecretValue{SecretKeyRef: &SecretKeyRef{Name: "redis", Key: "password"}},
+			PoolSize: 10,
+		},
+		Auth: AuthConfig{
+			OAuthClientID: "portal",
+			SessionSecret: SecretValue{Value: "session-s3cret-that-is-32-chars-long"},
+		},
+		OpenTelemetry: OpenTelemetryConfig{
+			Enabled:            true,
+			ServiceName:        "portal",
+			TracingSampling:    float64Ptr(0.25),
+			ResourceAttributes: map[string]string{"team": "platform", "deployment.environment": "prod"},
+			Headers:            map[string]string{"Authorization": "Bearer otel-s3cret"},
+		},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	config := externalTestConfig()
+
+	got, ok := Redact(config).(*ExternalServiceConfig)
+	if !ok {
+		t.Fatalf("Redact() = %T, want *ExternalServiceConfig", Redact(config))
+	}
+	if got == config {
+		t.Fatal("Redact() returned its argument instead of a copy")
+	}
+
+	if got.Database.Password.Value != RedactedValue || got.Auth.SessionSecret.Value != RedactedValue {
+		t.Errorf("literal secrets = %q, %q, want them redacted", got.Database.Password.Value, got.Auth.SessionSecret.Value)
+	}
+	if ref := got.Redis.Password.SecretKeyRef; ref == nil || ref.Name != "redis" || ref.Key != "password" || ref == config.Redis.Password.SecretKeyRef {
+		t.Errorf("Redis.Password = %#v, want a copy of the secretKeyRef", got.Redis.Password.SecretKeyRef)
+	}
+	if got.OpenTelemetry.Headers["Authorization"] != RedactedValue {
+		t.Errorf("Headers = %v, want the values redacted", got.OpenTelemetry.Headers)
+	}
+	if got.Database.Host != "db.example.com" || got.Auth.OAuthClientID != "portal" || got.OpenTelemetry.ResourceAttributes["deployment.environment"] != "prod" {
+		t.Errorf("Redact() changed values that are not sensitive: %+v", got)
+	}
+	if got.Auth.JWTSecret.Value != "" {
+		t.Errorf("JWTSecret = %q, want empty values to stay empty", got.Auth.JWTSecret.Value)
+	}
+
+	// The original is untouched
+	if config.Database.Password.Value != "pg-s3cret" || config.OpenTelemetry.Headers["Authorization"] != "Bearer otel-s3cret" {
+		t.Errorf("Redact() modified its argument: %+v", config)
+	}
+
+	if Redact(nil) != nil {
+		t.Error("Redact(nil) should be nil")
+	}
+	if value := Redact(config.Database).(PostgreSQLConfig); value.Password.Value != RedactedValue {
+		t.Errorf("Redact(PostgreSQLConfig) = %+v, want the password redacted", value)
+	}
+}
+
+func TestMarshalRedacted(t *testing.T) {
+	config := externalTestConfig()
+
+	yamlData, err := MarshalRedactedYAML(config)
+	if err != nil {
+		t.Fatalf("MarshalRedactedYAML() error = %v", err)
+	}
+	jsonData, err := MarshalRedactedJSON(config)
+	if err != nil {
+		t.Fatalf("MarshalRedactedJSON() error = %v", err)
+	}
+
+	for name, data := range map[string]string{"YAML": string(yamlData), "JSON": string(jsonData)} {
+		for _, secret := range []string{"pg-s3cret", "session-s3cret", "otel-s3cret"} {
+			if strings.Contains(data, secret) {
+				t.Errorf("%s output contains %q:\n%s", name, secret, data)
+			}
+		}
+	}
+	for _, want := range []string{"password: '***'", "name: redis", "Authorization: '***'", "host: db.example.com"} {
+		if !strings.Contains(string(yamlData), want) {
+			t.Errorf("YAML output does not contain %q:\n%s", want, yamlData)
+		}
+	}
+	for _, want := range []string{`"password":"***"`, `"secretKeyRef":{"key":"password","name":"redis"}`, `"headers":{"Authorization":"***"}`} {
+		if !strings.Contains(string(jsonData), want) {
+			t.Errorf("JSON output does not contain %q:\n%s", want, jsonData)
+		}
+	}
+}
+
+func TestSecretValueFormatting(t *testing.T) {
+	config := externalTestConfig()
+	for _, format := range []string{"%v", "%+v", "%#v"} {
+		if out := fmt.Sprintf(format, config.Database); strings.Contains(out, "pg-s3cret") {
+			t.Errorf("Sprintf(%q) = %s, want the password hidden", format, out)
+		}
+	}
+	if got := config.Redis.Password.String(); got != "secretKeyRef(redis/password)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// redactTestValues has a sensitive field whose validation prints its value
+type redactTestValues struct {
+	Mode   string `yaml:"mode" validate:"oneof=a b"`
+	Token  string `yaml:"token" validate:"omitempty,oneof=a b" sensitive:"true"`
+	Nested struct {
+		Keys []string `yaml:"keys" validate:"dive,min=3,oneof=abc def"`
+	} `yaml:"nested" sensitive:"true"`
+}
+
+func (v *redactTestValues) Validate() error {
+	var errs Errors
+	errs.Struct(v)
+	return errs.Err()
+}
+
+func TestValidationReportRedactsSensitiveValues(t *testing.T) {
+	doc := mustParseDocument(t, "values.yaml", "mode: c\ntoken: t0ps3cret\nnested:\n  keys: [abc, x9secret]\n")
+
+	var values redactTestValues
+	report, err := validateDocuments(&values, []*Document{doc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`values.yaml:1:1: mode: must be one of a, b (got "c")`,
+		`values.yaml:2:1: token: must be one of a, b (got "***")`,
+		`values.yaml:4:15: nested.keys[1]: must be one of abc, def (got "***")`,
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+		if issue.Path != "mode" && issue.Value != RedactedValue {
+			t.Errorf("%s: Value = %v, want %q", issue.Path, issue.Value, RedactedValue)
+		}
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Errors returned by Validate are redacted as well
+	if msg := values.Validate().Error(); strings.Contains(msg, "s3cret") || strings.Contains(msg, "x9secret") {
+		t.Errorf("Validate() error = %q, want the sensitive values hidden", msg)
+	}
+}
+
+func TestDiffRedactsSensitiveValues(t *testing.T) {
+	before, after := externalTestConfig(), externalTestConfig()
+	after.Database.Password.Value = "new-s3cret"
+	after.OpenTelemetry.Headers["X-Api-Key"] = "key-s3cret"
+	after.Database.Username = "admin"
+
+	var got []string
+	for _, c := range Diff(before, after) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"database.username: portal → admin",
+		"database.password: '***' → '***'",
+		"openTelemetry.headers.X-Api-Key: added '***'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
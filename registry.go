@@ -0,0 +1,136 @@
+package helmcharts
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Chart represents a Helm chart whose values are validated by a Go Values type
+type Chart struct {
+	// Name and Version are read from ChartYAML when the chart is registered
+	Name    string
+	Version string
+
+	// Dir is the chart directory relative to the repository root
+	Dir string
+
+	// ChartYAML and ValuesYAML are the contents of the chart's Chart.yaml and values.yaml
+	ChartYAML  []byte
+	ValuesYAML []byte
+
+	// NewValues returns a new, empty Values of the chart
+	NewValues func() Validatable
+}
+
+// ValuesFile is the path of the chart's values.yaml, as named in reports
+func (c *Chart) ValuesFile() string {
+	return path.Join(c.Dir, "values.yaml")
+}
+
+// Validate merges overlays onto the chart's values.yaml like helm install -f
+// does, decodes the result into a new Values and validates it
+func (c *Chart) Validate(overlays ...*Document) (Validatable, *ValidationReport, error) {
+	return c.ValidateWith(nil, overlays...)
+}
+
+// ValidateWith is Validate with additional checks, such as
+// RegistryAllowList.Check, whose failures are reported alongside
+func (c *Chart) ValidateWith(checks []Check, overlays ...*Document) (Validatable, *ValidationReport, error) {
+	base, err := ParseDocument(c.ValuesFile(), c.ValuesYAML)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := c.NewValues()
+	report, err := validateDocuments(v, append([]*Document{base}, overlays...), checks...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, report, nil
+}
+
+// Registry holds charts by name
+type Registry struct {
+	mu     sync.RWMutex
+	charts map[string]*Chart
+}
+
+// DefaultRegistry holds the charts of this repository, which register
+// themselves when their package is imported
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{charts: make(map[string]*Chart)}
+}
+
+// Register adds c to the registry, filling its name and version from its Chart.yaml
+func (r *Registry) Register(c Chart) error {
+	if c.NewValues == nil {
+		return fmt.Errorf("chart in %s has no Values constructor", c.Dir)
+	}
+	var meta struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(c.ChartYAML, &meta); err != nil {
+		return fmt.Errorf("%s: %w", path.Join(c.Dir, "Chart.yaml"), err)
+	}
+	if meta.Name == "" || meta.Version == "" {
+		return fmt.Errorf("%s: name and version are required", path.Join(c.Dir, "Chart.yaml"))
+	}
+	c.Name, c.Version = meta.Name, meta.Version
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.charts[c.Name]; ok {
+		return fmt.Errorf("chart %q is already registered", c.Name)
+	}
+	r.charts[c.Name] = &c
+	return nil
+}
+
+// MustRegister adds c to the DefaultRegistry and panics on failure
+func MustRegister(c Chart) {
+	if err := DefaultRegistry.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the chart registered under name
+func (r *Registry) Lookup(name string) (*Chart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.charts[name]
+	if !ok {
+		if len(r.charts) == 0 {
+			return nil, fmt.Errorf("unknown chart %q: no charts are registered", name)
+		}
+		return nil, fmt.Errorf("unknown chart %q; known charts are %s", name, strings.Join(r.names(), ", "))
+	}
+	return c, nil
+}
+
+// Charts returns the registered charts sorted by name
+func (r *Registry) Charts() []*Chart {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	charts := make([]*Chart, 0, len(r.charts))
+	for _, name := range r.names() {
+		charts = append(charts, r.charts[name])
+	}
+	return charts
+}
+
+func (r *Registry) names() []string {
+	names := make([]string, 0, len(r.charts))
+	for name := range r.charts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
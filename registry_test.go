@@ -0,0 +1,94 @@
+package helmcharts
+
+import (
+	"strings"
+	"testing"
+)
+
+func newRegistryTestChart(chartYAML string) Chart {
+	return Chart{
+		Dir:        "charts/web",
+		ChartYAML:  []byte(chartYAML),
+		ValuesYAML: []byte("service:\n  type: ClusterIP\n  port: 80\n"),
+		NewValues:  func() Validatable { return new(reportTestValues) },
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(newRegistryTestChart("name: web\nversion: 1.2.3\n")); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register(newRegistryTestChart("name: api\nversion: 0.1.0\n")); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	c, err := r.Lookup("web")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if c.Name != "web" || c.Version != "1.2.3" || c.ValuesFile() != "charts/web/values.yaml" {
+		t.Errorf("Lookup() = %+v", c)
+	}
+
+	var names []string
+	for _, c := range r.Charts() {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); got != "api,web" {
+		t.Errorf("Charts() = %s, want api,web", got)
+	}
+
+	if _, err := r.Lookup("admin"); err == nil || err.Error() != `unknown chart "admin"; known charts are api, web` {
+		t.Errorf("Lookup(admin) error = %v", err)
+	}
+}
+
+func TestRegistryRegisterErrors(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(newRegistryTestChart("name: web\nversion: 1.2.3\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	noValues := newRegistryTestChart("name: api\nversion: 0.1.0\n")
+	noValues.NewValues = nil
+
+	tests := []struct {
+		name  string
+		chart Chart
+		want  string
+	}{
+		{name: "duplicate", chart: newRegistryTestChart("name: web\nversion: 2.0.0\n"), want: `chart "web" is already registered`},
+		{name: "no constructor", chart: noValues, want: "has no Values constructor"},
+		{name: "no version", chart: newRegistryTestChart("name: api\n"), want: "charts/web/Chart.yaml: name and version are required"},
+		{name: "invalid Chart.yaml", chart: newRegistryTestChart("name: [api\n"), want: "charts/web/Chart.yaml: yaml:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Register(tt.chart)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Register() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartValidate(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(newRegistryTestChart("name: web\nversion: 1.2.3\n")); err != nil {
+		t.Fatal(err)
+	}
+	c, _ := r.Lookup("web")
+
+	overlay := mustParseDocument(t, "prod.yaml", "service:\n  port: 0\n")
+	v, report, err := c.Validate(overlay)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := v.(*reportTestValues).Service; got.Type != "ClusterIP" || got.Port != 0 {
+		t.Errorf("Service = %+v, want ClusterIP from values.yaml with port 0", got)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].String() != "prod.yaml:2:3: service.port: must be at least 1 (got 0)" {
+		t.Errorf("Validate() issues:\n%s", report)
+	}
+}
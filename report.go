@@ -0,0 +1,541 @@
+package helmcharts
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// Validatable is implemented by every chart Values type
+type Validatable interface {
+	Validate() error
+}
+
+// Document represents a parsed values file
+type Document struct {
+	File string
+	Root *yaml.Node
+
+	// sets holds the --set style flags the document was parsed from
+	sets []setExpr
+}
+
+// ParseDocument parses data read from file into a Document
+func ParseDocument(file string, data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &Document{File: file, Root: &root}, nil
+}
+
+// Decode decodes the document into v
+func (d *Document) Decode(v any) error {
+	if d.Root == nil || len(d.Root.Content) == 0 {
+		return nil
+	}
+	if err := d.Root.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", d.File, err)
+	}
+	return nil
+}
+
+// ValidationIssue represents a single validation failure located in a values file
+type ValidationIssue struct {
+	// Path is the YAML path of the offending value (e.g. main.hpa.maxReplicas)
+	Path string `json:"path,omitempty"`
+
+	// Location of the value in the values file, when it could be found
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+
+	// Tag is the validation rule that failed (e.g. required_if)
+	Tag   string `json:"tag,omitempty"`
+	Param string `json:"param,omitempty"`
+	Value any    `json:"value,omitempty"`
+
+	// Message is a human readable description of the failure
+	Message string `json:"message"`
+}
+
+// String formats the issue as file:line:column: path: message
+func (i ValidationIssue) String() string {
+	var b strings.Builder
+	if i.File != "" {
+		b.WriteString(i.File)
+		if i.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", i.Line, i.Column)
+		}
+		b.WriteString(": ")
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path)
+		b.WriteString(": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// ValidationReport collects the validation failures of a values document
+type ValidationReport struct {
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// Valid reports whether the report contains no issues
+func (r *ValidationReport) Valid() bool {
+	return r == nil || len(r.Issues) == 0
+}
+
+// Err returns the report as an error, or nil when it contains no issues
+func (r *ValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	return r
+}
+
+// Error implements the error interface
+func (r *ValidationReport) Error() string {
+	return r.String()
+}
+
+// String formats the report with one issue per line
+func (r *ValidationReport) String() string {
+	if r.Valid() {
+		return "no validation issues"
+	}
+	lines := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		lines[i] = issue.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewValidationReport converts err, as returned by v.Validate(), into a report.
+// Failures are located in the last of docs that defines their path, so overlays
+// take precedence over the files they override.
+func NewValidationReport(v any, err error, docs ...*Document) *ValidationReport {
+	report := &ValidationReport{}
+	if err == nil {
+		return report
+	}
+	root := reflect.TypeOf(v)
+	for _, e := range flattenErrors(err) {
+		var issue ValidationIssue
+		var fe *FieldError
+		if errors.As(e, &fe) {
+			var path yamlPathSegments
+			issue, path = newFieldIssue(root, fe)
+			if len(path) > 0 {
+				locateIssue(&issue, path, docs)
+			}
+		} else {
+			issue = ValidationIssue{Message: e.Error()}
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return report
+}
+
+// ValidateYAML strictly decodes data read from file into v, validates it and
+// returns the resulting report, which also lists keys that do not map to any
+// field. Template references in tpl fields are resolved before validating, so v
+// holds the rendered strings. The returned error is only set when data cannot
+// be decoded.
+func ValidateYAML(file string, data []byte, v Validatable) (*ValidationReport, error) {
+	doc, err := ParseDocument(file, data)
+	if err != nil {
+		return nil, err
+	}
+	return validateDocuments(v, []*Document{doc})
+}
+
+// hasIssue reports whether the report contains an issue at path
+func (r *ValidationReport) hasIssue(path string) bool {
+	for _, issue := range r.Issues {
+		if issue.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenErrors expands validator.ValidationErrors and joined errors into their parts
+func flattenErrors(err error) []error {
+	if ves, ok := err.(validator.ValidationErrors); ok {
+		out := make([]error, len(ves))
+		for i, fe := range ves {
+			out[i] = newFieldError(fe)
+		}
+		return out
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []error
+		for _, e := range joined.Unwrap() {
+			out = append(out, flattenErrors(e)...)
+		}
+		return out
+	}
+	return []error{err}
+}
+
+// newFieldIssue converts a field error into an unlocated issue
+func newFieldIssue(root reflect.Type, fe *FieldError) (ValidationIssue, yamlPathSegments) {
+	segments := parseNamespace(fe.Field)
+	path, parent := yamlPath(root, segments)
+	value := fe.Value
+	if sensitivePath(root, segments) {
+		value = redactAny(value)
+	}
+	msg := fe.Message
+	if msg == "" {
+		msg = tagMessage(fe.Tag, fe.Param, fe.Kind, value, parent)
+	}
+	return ValidationIssue{
+		Path:    path.String(),
+		Tag:     fe.Tag,
+		Param:   fe.Param,
+		Value:   value,
+		Message: msg,
+	}, path
+}
+
+// pathSegment is one element of a struct namespace or YAML path
+type pathSegment struct {
+	name  string
+	index int
+	key   bool // name is a map key rather than a field name
+	item  bool // index addresses a sequence item
+	named bool // name is the name of a sequence item
+}
+
+type yamlPathSegments []pathSegment
+
+// String renders the segments as a dotted YAML path with [n] list indices
+func (p yamlPathSegments) String() string {
+	var b strings.Builder
+	for i, seg := range p {
+		switch {
+		case seg.item:
+			fmt.Fprintf(&b, "[%d]", seg.index)
+		case seg.named && !isPlainKey(seg.name):
+			fmt.Fprintf(&b, "[name=%q]", seg.name)
+		case seg.named:
+			fmt.Fprintf(&b, "[name=%s]", seg.name)
+		case seg.key && !isPlainKey(seg.name):
+			fmt.Fprintf(&b, "[%q]", seg.name)
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(seg.name)
+		}
+	}
+	return b.String()
+}
+
+func isPlainKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if r == '.' || r == '[' || r == ']' || r == '"' || r == ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNamespace splits a validator namespace such as Values.Main.Ports[0].Port
+func parseNamespace(ns string) []pathSegment {
+	var segments []pathSegment
+	for len(ns) > 0 {
+		switch ns[0] {
+		case '.':
+			ns = ns[1:]
+		case '[':
+			end := strings.IndexByte(ns, ']')
+			if end < 0 {
+				end = len(ns)
+			}
+			inner := ns[1:end]
+			if n, err := strconv.Atoi(inner); err == nil {
+				segments = append(segments, pathSegment{index: n, item: true, name: inner})
+			} else {
+				segments = append(segments, pathSegment{name: inner, key: true})
+			}
+			ns = ns[min(end+1, len(ns)):]
+		default:
+			end := strings.IndexAny(ns, ".[")
+			if end < 0 {
+				end = len(ns)
+			}
+			segments = append(segments, pathSegment{name: ns[:end]})
+			ns = ns[end:]
+		}
+	}
+	return segments
+}
+
+// yamlPath translates Go field names into YAML keys by walking root alongside
+// the segments. It also returns the struct type that holds the last field.
+func yamlPath(root reflect.Type, segments []pathSegment) (yamlPathSegments, reflect.Type) {
+	out := make(yamlPathSegments, 0, len(segments))
+	t := root
+	var parent reflect.Type
+	for _, seg := range segments {
+		t = derefType(t)
+		switch {
+		case seg.item:
+			out = append(out, seg)
+			if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				t = t.Elem()
+			}
+		case seg.key:
+			out = append(out, seg)
+			if t != nil && t.Kind() == reflect.Map {
+				t = t.Elem()
+			}
+		default:
+			parent = t
+			if t == nil || t.Kind() != reflect.Struct {
+				out = append(out, seg)
+				t = nil
+				continue
+			}
+			f, ok := t.FieldByName(seg.name)
+			if !ok {
+				out = append(out, seg)
+				t = nil
+				continue
+			}
+			out = append(out, pathSegment{name: yamlFieldName(f)})
+			t = f.Type
+		}
+	}
+	return out, derefType(parent)
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// yamlFieldName returns the key yaml.v3 uses for a struct field
+func yamlFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// siblingYAMLName translates the Go name of a field of parent into its YAML key
+func siblingYAMLName(parent reflect.Type, name string) string {
+	if parent != nil && parent.Kind() == reflect.Struct {
+		if f, ok := parent.FieldByName(name); ok {
+			return yamlFieldName(f)
+		}
+	}
+	return name
+}
+
+// locateIssue fills the file, line and column of issue from the last document defining its path
+func locateIssue(issue *ValidationIssue, segments yamlPathSegments, docs []*Document) {
+	var best *yaml.Node
+	var bestFile string
+	bestDepth := -1
+	for i := len(docs) - 1; i >= 0; i-- {
+		doc := docs[i]
+		if doc == nil || doc.Root == nil {
+			continue
+		}
+		node, depth := findNode(doc.Root, segments)
+		if node != nil && depth > bestDepth {
+			best, bestFile, bestDepth = node, doc.File, depth
+		}
+		if depth == len(segments) {
+			break
+		}
+	}
+	if best == nil {
+		return
+	}
+	issue.File = bestFile
+	issue.Line = best.Line
+	issue.Column = best.Column
+}
+
+// findNode walks root along segments and returns the node locating the deepest
+// matched segment together with the number of segments matched. Mapping entries
+// are located at their key so missing children point at the parent's key.
+func findNode(root *yaml.Node, segments []pathSegment) (*yaml.Node, int) {
+	if root.Kind == yaml.DocumentNode && len(root.Content) == 0 {
+		return nil, -1
+	}
+	var located *yaml.Node
+	depth := walkPath(root, segments, func(key, value *yaml.Node) {
+		if key != nil {
+			located = key
+		} else {
+			located = value
+		}
+	})
+	return located, depth
+}
+
+// walkPath follows segments from root, calling visit with the root node and
+// then with the key node (nil for sequence items) and the value node of each
+// matched segment. It returns the number of segments matched.
+func walkPath(root *yaml.Node, segments []pathSegment, visit func(key, value *yaml.Node)) int {
+	node := root
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return 0
+		}
+		node = node.Content[0]
+	}
+	visit(nil, node)
+	for depth, seg := range segments {
+		node = resolveAlias(node)
+		var key, value *yaml.Node
+		switch {
+		case seg.item:
+			if node.Kind == yaml.SequenceNode && seg.index < len(node.Content) {
+				value = node.Content[seg.index]
+			}
+		case seg.named:
+			if node.Kind == yaml.SequenceNode {
+				for _, item := range node.Content {
+					if name := mappingValue(resolveAlias(item), "name"); name != nil && name.Value == seg.name {
+						value = item
+						break
+					}
+				}
+			}
+		case node.Kind == yaml.MappingNode:
+			if i := mappingIndex(node, seg.name); i >= 0 {
+				key, value = node.Content[i], node.Content[i+1]
+			}
+		}
+		if value == nil {
+			return depth
+		}
+		visit(key, value)
+		node = value
+	}
+	return len(segments)
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// tagMessage returns a human readable message for a failed validation tag
+func tagMessage(tag, param string, kind reflect.Kind, value any, parent reflect.Type) string {
+	switch tag {
+	case "required":
+		return "is required"
+	case "required_if":
+		fields := strings.Fields(param)
+		var conds []string
+		for i := 0; i+1 < len(fields); i += 2 {
+			conds = append(conds, fmt.Sprintf("%s is %s", siblingYAMLName(parent, fields[i]), fields[i+1]))
+		}
+		return "is required when " + strings.Join(conds, " and ")
+	case "oneof":
+		return fmt.Sprintf("must be one of %s%s", strings.Join(strings.Fields(param), ", "), gotValue(value))
+	case "min":
+		return boundMessage("at least", param, kind, value)
+	case "max":
+		return boundMessage("at most", param, kind, value)
+	case "gtefield":
+		return fmt.Sprintf("must be greater than or equal to %s%s", siblingYAMLName(parent, param), gotValue(value))
+	case "gtfield":
+		return fmt.Sprintf("must be greater than %s%s", siblingYAMLName(parent, param), gotValue(value))
+	case "ltefield":
+		return fmt.Sprintf("must be less than or equal to %s%s", siblingYAMLName(parent, param), gotValue(value))
+	case "fqdn":
+		return "must be a fully qualified domain name" + gotValue(value)
+	case "fqdn|ip":
+		return "must be a fully qualified domain name or an IP address" + gotValue(value)
+	case "ip":
+		return "must be an IP address" + gotValue(value)
+	case "cidr":
+		return "must be a CIDR block such as 10.0.0.0/8" + gotValue(value)
+	case "url":
+		return "must be an absolute URL" + gotValue(value)
+	case "duration":
+		return "must be a duration such as 30s, 5m or 1h30m" + gotValue(value)
+	case "resource_quantity":
+		return "must be a Kubernetes resource quantity such as 500m, 0.5 or 128Mi" + gotValue(value)
+	case "lte_limit":
+		return fmt.Sprintf("must not exceed the limit of %s%s", param, gotValue(value))
+	case "filepath":
+		return "must be a file path" + gotValue(value)
+	case "port_string":
+		return "must be a port number between 1 and 65535" + gotValue(value)
+	case "http_token":
+		return "must be an HTTP token of letters, digits and !#$%&'*+-.^_`|~" + gotValue(value)
+	case "host_port":
+		return "must be a host name or IP address and a port such as redis:6379 or [::1]:6379" + gotValue(value)
+	case "image_reference":
+		if s, ok := value.(string); ok {
+			var ire *ImageReferenceError
+			if _, err := ParseImageReference(s); errors.As(err, &ire) {
+				return fmt.Sprintf("must be a container image reference: %s%s", ire.Reason, gotValue(value))
+			}
+		}
+		return "must be a container image reference such as nginx:1.27" + gotValue(value)
+	case "cors_origin":
+		if s, ok := value.(string); ok {
+			var coe *CORSOriginError
+			if _, err := ParseCORSOrigin(s); errors.As(err, &coe) {
+				return fmt.Sprintf("must be a CORS origin: %s%s", coe.Reason, gotValue(value))
+			}
+		}
+		return "must be * or a CORS origin such as https://portal.tacokumo.dev" + gotValue(value)
+	default:
+		return fmt.Sprintf("failed %q validation%s", tag, gotValue(value))
+	}
+}
+
+// boundMessage describes min/max failures according to the kind of the field
+func boundMessage(bound, param string, kind reflect.Kind, value any) string {
+	switch kind {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s characters long", bound, param)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("must contain %s %s items", bound, param)
+	default:
+		return fmt.Sprintf("must be %s %s%s", bound, param, gotValue(value))
+	}
+}
+
+// gotValue formats the offending value for inclusion in a message
+func gotValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		if v == "" {
+			return ""
+		}
+		return fmt.Sprintf(" (got %q)", v)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf(" (got %v)", v)
+	default:
+		return ""
+	}
+}
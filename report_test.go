@@ -0,0 +1,119 @@
+package helmcharts
+
+import (
+	"errors"
+	"testing"
+)
+
+type reportTestValues struct {
+	Service Service `yaml:"service"`
+	Ingress Ingress `yaml:"ingress"`
+}
+
+func (v *reportTestValues) Validate() error {
+	return ValidateStruct(v)
+}
+
+func TestValidateYAMLReport(t *testing.T) {
+	data := []byte(`service:
+  type: Bogus
+  port: 80
+ingress:
+  enabled: true
+  hosts:
+    - host: invalid_host
+      paths:
+        - path: /
+          pathType: Prefix
+`)
+
+	var values reportTestValues
+	report, err := ValidateYAML("values.yaml", data, &values)
+	if err != nil {
+		t.Fatalf("ValidateYAML() error = %v", err)
+	}
+
+	want := []ValidationIssue{
+		{
+			Path:    "service.type",
+			File:    "values.yaml",
+			Line:    2,
+			Column:  3,
+			Tag:     "oneof",
+			Message: `must be one of ClusterIP, NodePort, LoadBalancer, ExternalName (got "Bogus")`,
+		},
+		{
+			Path:    "ingress.className",
+			File:    "values.yaml",
+			Line:    4,
+			Column:  1,
+			Tag:     "required_if",
+			Message: "is required when enabled is true",
+		},
+		{
+			Path:    "ingress.hosts[0].host",
+			File:    "values.yaml",
+			Line:    7,
+			Column:  7,
+			Tag:     "fqdn",
+			Message: `must be a fully qualified domain name (got "invalid_host")`,
+		},
+	}
+
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d:\n%s", len(report.Issues), len(want), report)
+	}
+	for i, w := range want {
+		got := report.Issues[i]
+		if got.Path != w.Path || got.File != w.File || got.Line != w.Line || got.Column != w.Column || got.Tag != w.Tag || got.Message != w.Message {
+			t.Errorf("issue %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	wantString := `values.yaml:2:3: service.type: must be one of ClusterIP, NodePort, LoadBalancer, ExternalName (got "Bogus")`
+	if got := report.Issues[0].String(); got != wantString {
+		t.Errorf("String() = %q, want %q", got, wantString)
+	}
+	if report.Valid() || report.Err() == nil {
+		t.Error("report with issues should not be valid")
+	}
+}
+
+func TestValidationReportLocatesInLastDocument(t *testing.T) {
+	base, err := ParseDocument("values.yaml", []byte("service:\n  type: ClusterIP\n  port: 80\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	overlay, err := ParseDocument("prod.yaml", []byte("service:\n  port: 0\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	values := reportTestValues{Service: Service{Type: "ClusterIP"}}
+	report := NewValidationReport(&values, values.Validate(), base, overlay)
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
+	}
+	got := report.Issues[0]
+	if got.File != "prod.yaml" || got.Line != 2 || got.Path != "service.port" {
+		t.Errorf("issue = %+v, want service.port located in prod.yaml:2", got)
+	}
+	if got.Message != "must be at least 1 (got 0)" {
+		t.Errorf("Message = %q", got.Message)
+	}
+}
+
+func TestValidationReportPlainErrors(t *testing.T) {
+	err := errors.Join(errors.New("first"), errors.New("second"))
+	report := NewValidationReport(&reportTestValues{}, err)
+	if len(report.Issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(report.Issues))
+	}
+	if report.String() != "first\nsecond" {
+		t.Errorf("String() = %q", report.String())
+	}
+
+	if !NewValidationReport(&reportTestValues{}, nil).Valid() {
+		t.Error("report without error should be valid")
+	}
+}
@@ -0,0 +1,459 @@
+package helmcharts
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SchemaDraft07 is the JSON Schema dialect understood by Helm
+const SchemaDraft07 = "http://json-schema.org/draft-07/schema#"
+
+// Patterns used for custom validations that have no JSON Schema format
+const (
+	resourceQuantitySchemaPattern = `^\+?([0-9]+(\.[0-9]*)?|\.[0-9]+)([numkMGTPE]|[KMGTPE]i|[eE][+-]?[0-9]+)?$`
+	durationSchemaPattern         = `^[-+]?(0|([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+	cidrSchemaPattern             = `^[0-9A-Fa-f:.]+/[0-9]{1,3}$`
+	portStringSchemaPattern       = `^[0-9]{1,5}$`
+	imageReferenceSchemaPattern   = `^((([a-zA-Z0-9-]+\.)+[a-zA-Z0-9-]+(:[0-9]+)?|[a-zA-Z0-9-]+:[0-9]+|localhost)/)?[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*(:[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(@[a-z0-9]+([+._-][a-z0-9]+)*:[a-f0-9]{32,})?$`
+	templateSchemaPattern         = `\{\{.*\}\}`
+)
+
+// Schema represents a JSON Schema draft-07 document or subschema
+type Schema struct {
+	Schema               string            `json:"$schema,omitempty"`
+	Type                 any               `json:"type,omitempty"`
+	Properties           *SchemaProperties `json:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	AdditionalProperties any               `json:"additionalProperties,omitempty"`
+	Items                *Schema           `json:"items,omitempty"`
+	Enum                 []any             `json:"enum,omitempty"`
+	Const                *any              `json:"const,omitempty"`
+	Format               string            `json:"format,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	Minimum              *float64          `json:"minimum,omitempty"`
+	Maximum              *float64          `json:"maximum,omitempty"`
+	MinLength            *int              `json:"minLength,omitempty"`
+	MaxLength            *int              `json:"maxLength,omitempty"`
+	MinItems             *int              `json:"minItems,omitempty"`
+	MaxItems             *int              `json:"maxItems,omitempty"`
+	Not                  *Schema           `json:"not,omitempty"`
+	AnyOf                []*Schema         `json:"anyOf,omitempty"`
+	AllOf                []*Schema         `json:"allOf,omitempty"`
+	If                   *Schema           `json:"if,omitempty"`
+	Then                 *Schema           `json:"then,omitempty"`
+}
+
+// SchemaProperties holds the properties of an object schema in field order
+type SchemaProperties struct {
+	names   []string
+	schemas map[string]*Schema
+}
+
+// Set adds or replaces the schema of property name
+func (p *SchemaProperties) Set(name string, s *Schema) {
+	if p.schemas == nil {
+		p.schemas = make(map[string]*Schema)
+	}
+	if _, ok := p.schemas[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.schemas[name] = s
+}
+
+// Get returns the schema of property name
+func (p *SchemaProperties) Get(name string) *Schema {
+	if p == nil {
+		return nil
+	}
+	return p.schemas[name]
+}
+
+// MarshalJSON encodes the properties keeping their field order
+func (p *SchemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// GenerateSchema returns the values.schema.json document for the Values type of v,
+// translating its validate struct tags into JSON Schema constraints
+func GenerateSchema(v any) ([]byte, error) {
+	s := SchemaFor(reflect.TypeOf(v))
+	s.Schema = SchemaDraft07
+	// Helm injects global values into every chart, including subcharts
+	if s.Properties != nil {
+		s.Properties.Set("global", &Schema{Type: "object"})
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// SchemaFor returns the JSON Schema describing values of type t
+func SchemaFor(t reflect.Type) *Schema {
+	return typeSchema(t, nil, false)
+}
+
+// typeSchema builds the schema of t constrained by the validate rules that apply to it
+func typeSchema(t reflect.Type, rules []string, templated bool) *Schema {
+	pointer := t.Kind() == reflect.Pointer
+	t = derefType(t)
+	own, elem := splitDive(rules)
+
+	var s *Schema
+	switch t.Kind() {
+	case reflect.Struct:
+		s = structSchema(t)
+	case reflect.Slice, reflect.Array:
+		s = &Schema{Type: "array", Items: typeSchema(t.Elem(), elem, templated)}
+	case reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), elem, templated)}
+	case reflect.String:
+		s = &Schema{Type: "string"}
+	case reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		s = &Schema{}
+	}
+
+	if t.Kind() == reflect.String && hasRule(own, "resource_quantity") {
+		// Quantities such as cpu: 1 are plain YAML numbers
+		s.Type = []string{"string", "number"}
+	}
+
+	c, omitempty := ruleConstraints(t, own)
+	if c.empty() {
+		return s
+	}
+	if templated && t.Kind() == reflect.String {
+		// The value is rendered with tpl, so a template placeholder is also accepted
+		c = &Schema{AnyOf: []*Schema{c, {Pattern: templateSchemaPattern}}}
+	}
+	if omitempty && !pointer {
+		zero := reflect.Zero(t).Interface()
+		s.AnyOf = []*Schema{{Const: &zero}, c}
+		return s
+	}
+	s.merge(c)
+	return s
+}
+
+// structSchema builds the object schema of struct type t
+func structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: &SchemaProperties{}, AdditionalProperties: false}
+	type condition struct {
+		field, value string
+		then         *Schema
+	}
+	var conditions []*condition
+
+	for _, f := range structFields(t) {
+		name := yamlFieldName(f)
+		rules := splitRules(f.Tag.Get("validate"))
+		s.Properties.Set(name, typeSchema(f.Type, rules, f.Tag.Get("tpl") == "true"))
+
+		own, _ := splitDive(rules)
+		for _, rule := range own {
+			tag, param, _ := strings.Cut(rule, "=")
+			switch tag {
+			case "required":
+				s.Required = append(s.Required, name)
+			case "required_if":
+				params := strings.Fields(param)
+				for i := 0; i+1 < len(params); i += 2 {
+					var cond *condition
+					for _, c := range conditions {
+						if c.field == params[i] && c.value == params[i+1] {
+							cond = c
+						}
+					}
+					if cond == nil {
+						cond = &condition{field: params[i], value: params[i+1], then: &Schema{}}
+						conditions = append(conditions, cond)
+					}
+					cond.then.Required = append(cond.then.Required, name)
+					if nonZero := nonZeroSchema(derefType(f.Type)); nonZero != nil {
+						if cond.then.Properties == nil {
+							cond.then.Properties = &SchemaProperties{}
+						}
+						cond.then.Properties.Set(name, nonZero)
+					}
+				}
+			}
+		}
+	}
+
+	for _, c := range conditions {
+		field, ok := t.FieldByName(c.field)
+		if !ok {
+			continue
+		}
+		name := yamlFieldName(field)
+		value := parseRuleValue(derefType(field.Type), c.value)
+		ifSchema := &Schema{Properties: &SchemaProperties{}, Required: []string{name}}
+		ifSchema.Properties.Set(name, &Schema{Const: &value})
+		s.AllOf = append(s.AllOf, &Schema{If: ifSchema, Then: c.then})
+	}
+	return s
+}
+
+// structFields returns the exported fields of t, flattening inlined structs
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") && derefType(f.Type).Kind() == reflect.Struct {
+			fields = append(fields, structFields(derefType(f.Type))...)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// ruleConstraints translates validate rules into constraints on a value of type t
+func ruleConstraints(t reflect.Type, rules []string) (*Schema, bool) {
+	c := &Schema{}
+	omitempty := false
+	for _, rule := range rules {
+		tag, param, _ := strings.Cut(rule, "=")
+		switch tag {
+		case "omitempty":
+			omitempty = true
+		case "required":
+			if nonZero := nonZeroSchema(t); nonZero != nil {
+				c.merge(nonZero)
+			}
+		case "oneof":
+			for _, v := range oneOfValues(param) {
+				c.Enum = append(c.Enum, parseRuleValue(t, v))
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			c.setBound(t, tag == "min", n)
+		default:
+			if alt := formatSchemas(tag); alt != nil {
+				c.merge(alt)
+			}
+		}
+	}
+	return c, omitempty
+}
+
+// formatSchemas translates string format tags, including fqdn|ip alternatives
+func formatSchemas(tag string) *Schema {
+	var alts []*Schema
+	for _, alt := range strings.Split(tag, "|") {
+		switch alt {
+		case "fqdn":
+			alts = append(alts, &Schema{Format: "hostname"})
+		case "ip":
+			alts = append(alts, &Schema{Format: "ipv4"}, &Schema{Format: "ipv6"})
+		case "url":
+			alts = append(alts, &Schema{Format: "uri"})
+		case "cidr":
+			alts = append(alts, &Schema{Pattern: cidrSchemaPattern})
+		case "duration":
+			alts = append(alts, &Schema{Pattern: durationSchemaPattern})
+		case "resource_quantity":
+			alts = append(alts, &Schema{Pattern: resourceQuantitySchemaPattern})
+		case "port_string":
+			alts = append(alts, &Schema{Pattern: portStringSchemaPattern})
+		case "image_reference":
+			alts = append(alts, &Schema{Pattern: imageReferenceSchemaPattern})
+		}
+	}
+	switch len(alts) {
+	case 0:
+		return nil
+	case 1:
+		return alts[0]
+	default:
+		return &Schema{AnyOf: alts}
+	}
+}
+
+// nonZeroSchema rejects the zero value of scalar types, mirroring required
+func nonZeroSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		one := 1
+		return &Schema{MinLength: &one}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		var zero any = 0
+		return &Schema{Not: &Schema{Const: &zero}}
+	default:
+		return nil
+	}
+}
+
+// setBound applies a min or max rule according to the kind of t
+func (s *Schema) setBound(t reflect.Type, lower bool, n float64) {
+	switch t.Kind() {
+	case reflect.String:
+		l := int(n)
+		if lower {
+			s.MinLength = &l
+		} else {
+			s.MaxLength = &l
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		l := int(n)
+		if lower {
+			s.MinItems = &l
+		} else {
+			s.MaxItems = &l
+		}
+	default:
+		if lower {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// merge copies the constraints set in c into s
+func (s *Schema) merge(c *Schema) {
+	if c.Enum != nil {
+		s.Enum = c.Enum
+	}
+	if c.Const != nil {
+		s.Const = c.Const
+	}
+	if c.Format != "" {
+		s.Format = c.Format
+	}
+	if c.Pattern != "" {
+		s.Pattern = c.Pattern
+	}
+	if c.Minimum != nil {
+		s.Minimum = c.Minimum
+	}
+	if c.Maximum != nil {
+		s.Maximum = c.Maximum
+	}
+	if c.MinLength != nil {
+		s.MinLength = c.MinLength
+	}
+	if c.MaxLength != nil {
+		s.MaxLength = c.MaxLength
+	}
+	if c.MinItems != nil {
+		s.MinItems = c.MinItems
+	}
+	if c.MaxItems != nil {
+		s.MaxItems = c.MaxItems
+	}
+	if c.Not != nil {
+		s.Not = c.Not
+	}
+	s.AnyOf = append(s.AnyOf, c.AnyOf...)
+}
+
+// empty reports whether s carries no constraint
+func (s *Schema) empty() bool {
+	return s.Enum == nil && s.Const == nil && s.Format == "" && s.Pattern == "" &&
+		s.Minimum == nil && s.Maximum == nil && s.MinLength == nil && s.MaxLength == nil &&
+		s.MinItems == nil && s.MaxItems == nil && s.Not == nil && len(s.AnyOf) == 0
+}
+
+// splitRules splits a validate tag into its comma separated rules
+func splitRules(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// hasRule reports whether rules contain the rule tag
+func hasRule(rules []string, tag string) bool {
+	for _, rule := range rules {
+		if name, _, _ := strings.Cut(rule, "="); name == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// splitDive separates the rules of a container from the rules of its elements
+func splitDive(rules []string) (own, elem []string) {
+	for i, rule := range rules {
+		if rule == "dive" {
+			return rules[:i], rules[i+1:]
+		}
+	}
+	return rules, nil
+}
+
+var oneOfValuePattern = regexp.MustCompile(`'[^']*'|\S+`)
+
+// oneOfValues splits a oneof parameter the way the validator does
+func oneOfValues(param string) []string {
+	values := oneOfValuePattern.FindAllString(param, -1)
+	for i, v := range values {
+		values[i] = strings.Trim(v, "'")
+	}
+	return values
+}
+
+// parseRuleValue converts a rule parameter into a JSON value of the kind of t
+func parseRuleValue(t reflect.Type, v string) any {
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
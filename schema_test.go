@@ -0,0 +1,73 @@
+package helmcharts
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type schemaTestValues struct {
+	Enabled  bool     `yaml:"enabled"`
+	Name     string   `yaml:"name" validate:"required"`
+	Type     string   `yaml:"type,omitempty" validate:"omitempty,oneof=ClusterIP NodePort"`
+	Replicas int      `yaml:"replicas" validate:"min=1,max=10"`
+	Host     string   `yaml:"host" validate:"required_if=Enabled true,omitempty,fqdn"`
+	Sources  []string `yaml:"sources,omitempty" validate:"dive,cidr"`
+	Aliases  []string `yaml:"aliases,omitempty" validate:"dive,fqdn" tpl:"true"`
+	CPU      string   `yaml:"cpu,omitempty" validate:"omitempty,resource_quantity"`
+	Timeout  *int     `yaml:"timeout,omitempty" validate:"omitempty,min=1"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	got, err := json.Marshal(SchemaFor(reflect.TypeFor[schemaTestValues]()))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	want := `{"type":"object","properties":{` +
+		`"enabled":{"type":"boolean"},` +
+		`"name":{"type":"string","minLength":1},` +
+		`"type":{"type":"string","anyOf":[{"const":""},{"enum":["ClusterIP","NodePort"]}]},` +
+		`"replicas":{"type":"integer","minimum":1,"maximum":10},` +
+		`"host":{"type":"string","anyOf":[{"const":""},{"format":"hostname"}]},` +
+		`"sources":{"type":"array","items":{"type":"string","pattern":"^[0-9A-Fa-f:.]+/[0-9]{1,3}$"}},` +
+		`"aliases":{"type":"array","items":{"type":"string","anyOf":[{"format":"hostname"},{"pattern":"\\{\\{.*\\}\\}"}]}},` +
+		`"cpu":{"type":["string","number"],"anyOf":[{"const":""},{"pattern":"^\\+?([0-9]+(\\.[0-9]*)?|\\.[0-9]+)([numkMGTPE]|[KMGTPE]i|[eE][+-]?[0-9]+)?$"}]},` +
+		`"timeout":{"type":"integer","minimum":1}},` +
+		`"required":["name"],` +
+		`"additionalProperties":false,` +
+		`"allOf":[{"if":{"properties":{"enabled":{"const":true}},"required":["enabled"]},` +
+		`"then":{"properties":{"host":{"minLength":1}},"required":["host"]}}]}`
+
+	if string(got) != want {
+		t.Errorf("SchemaFor() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateSchema(t *testing.T) {
+	data, err := GenerateSchema(&reportTestValues{})
+	if err != nil {
+		t.Fatalf("GenerateSchema() error = %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("generated schema is not valid JSON: %v", err)
+	}
+	if doc["$schema"] != SchemaDraft07 {
+		t.Errorf("$schema = %v, want %s", doc["$schema"], SchemaDraft07)
+	}
+
+	properties := doc["properties"].(map[string]any)
+	for _, name := range []string{"service", "ingress", "global"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("schema is missing property %q", name)
+		}
+	}
+
+	service := properties["service"].(map[string]any)["properties"].(map[string]any)
+	port := service["port"].(map[string]any)
+	if port["minimum"] != 1.0 || port["maximum"] != 65535.0 {
+		t.Errorf("service.port schema = %v, want minimum 1 and maximum 65535", port)
+	}
+}
@@ -0,0 +1,137 @@
+package helmcharts
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretValue represents a sensitive value given either literally or as a
+// reference to a key of a Kubernetes Secret. In YAML a plain string is a
+// literal, so existing values files keep working:
+//
+//	password: s3cret
+//	password:
+//	  secretKeyRef:
+//	    name: postgres
+//	    key: password
+type SecretValue struct {
+	Value        string        `yaml:"value,omitempty"`
+	SecretKeyRef *SecretKeyRef `yaml:"secretKeyRef,omitempty"`
+}
+
+// SecretKeyRef selects a key of a Kubernetes Secret, like an env var's valueFrom.secretKeyRef
+type SecretKeyRef struct {
+	Name     string `yaml:"name" validate:"required"`
+	Key      string `yaml:"key" validate:"required"`
+	Optional bool   `yaml:"optional,omitempty"`
+}
+
+// secretValueKeys are the keys of the mapping form of a SecretValue
+var secretValueKeys = []string{"value", "secretKeyRef"}
+
+// IsZero reports whether neither a literal nor a reference is set
+func (s SecretValue) IsZero() bool {
+	return s.Value == "" && s.SecretKeyRef == nil
+}
+
+// IsLiteral reports whether the value is given literally in the values file
+func (s SecretValue) IsLiteral() bool {
+	return s.Value != ""
+}
+
+// String describes the value without revealing a literal secret, so that
+// printing a config with %v or %+v does not leak it
+func (s SecretValue) String() string {
+	switch {
+	case s.SecretKeyRef != nil:
+		return fmt.Sprintf("secretKeyRef(%s/%s)", s.SecretKeyRef.Name, s.SecretKeyRef.Key)
+	case s.Value != "":
+		return RedactedValue
+	default:
+		return ""
+	}
+}
+
+// GoString is String for the %#v verb
+func (s SecretValue) GoString() string {
+	return s.String()
+}
+
+// UnmarshalYAML decodes a plain string as a literal and a mapping as a
+// literal value and/or a secretKeyRef
+func (s *SecretValue) UnmarshalYAML(node *yaml.Node) error {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*s = SecretValue{Value: node.Value}
+		return nil
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; !slices.Contains(secretValueKeys, key.Value) {
+				return fmt.Errorf("line %d: unknown secret key %q; want value or secretKeyRef", key.Line, key.Value)
+			}
+		}
+		type plain SecretValue
+		var p plain
+		if err := node.Decode(&p); err != nil {
+			return err
+		}
+		*s = SecretValue(p)
+		return nil
+	default:
+		return fmt.Errorf("line %d: a secret must be a string or a mapping with value or secretKeyRef", node.Line)
+	}
+}
+
+// MarshalYAML encodes a literal without a reference as a plain string
+func (s SecretValue) MarshalYAML() (any, error) {
+	if s.SecretKeyRef == nil {
+		return s.Value, nil
+	}
+	type plain SecretValue
+	return plain(s), nil
+}
+
+// Secret collects the failures of the secret stored in field: a secret must not
+// set both a literal and a reference, a required secret must set either and
+// the reference must name a Secret and a key
+func (e *Errors) Secret(field string, s SecretValue, required bool) {
+	switch {
+	case s.IsZero():
+		if required {
+			e.add(&FieldError{Field: field, Tag: "required"})
+		}
+	case s.Value != "" && s.SecretKeyRef != nil:
+		e.Add(field, "secret_value", "must set either a literal value or secretKeyRef, not both")
+	case s.SecretKeyRef != nil:
+		e.Nested(joinField(field, "SecretKeyRef"), ValidateStruct(s.SecretKeyRef))
+	}
+}
+
+// SecretMinLength collects a failure on field when the literal value of s is
+// shorter than n characters. References are resolved at runtime and not checked.
+func (e *Errors) SecretMinLength(field string, s SecretValue, n int) {
+	if s.Value != "" && len(s.Value) < n {
+		e.add(&FieldError{Field: field, Tag: "min", Param: fmt.Sprint(n), Kind: reflect.String})
+	}
+}
+
+// StrictSecrets is a Check that rejects literal secrets, so that credentials
+// are only ever read from Kubernetes Secrets and never committed in values files
+func StrictSecrets(values any) error {
+	var errs Errors
+	walkValues("", reflect.ValueOf(values), "", func(field string, v reflect.Value, _ reflect.StructTag) bool {
+		s, ok := v.Interface().(SecretValue)
+		if !ok {
+			return false
+		}
+		if s.IsLiteral() {
+			errs.Add(field, "strict_secret", "must be a secretKeyRef; literal secrets are not allowed")
+		}
+		return true
+	})
+	return errs.Err()
+}
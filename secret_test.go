@@ -0,0 +1,231 @@
+package helmcharts
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSecretValueYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want SecretValue
+	}{
+		{
+			name: "plain string is a literal",
+			data: "s3cret",
+			want: SecretValue{Value: "s3cret"},
+		},
+		{
+			name: "number is a literal",
+			data: "12345",
+			want: SecretValue{Value: "12345"},
+		},
+		{
+			name: "secret key reference",
+			data: "secretKeyRef:\n  name: postgres\n  key: password\n",
+			want: SecretValue{SecretKeyRef: &SecretKeyRef{Name: "postgres", Key: "password"}},
+		},
+		{
+			name: "optional reference",
+			data: "secretKeyRef: {name: redis, key: password, optional: true}\n",
+			want: SecretValue{SecretKeyRef: &SecretKeyRef{Name: "redis", Key: "password", Optional: true}},
+		},
+		{
+			name: "literal and reference",
+			data: "value: s3cret\nsecretKeyRef: {name: postgres, key: password}\n",
+			want: SecretValue{Value: "s3cret", SecretKeyRef: &SecretKeyRef{Name: "postgres", Key: "password"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SecretValue
+			if err := yaml.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got.Value != tt.want.Value || (got.SecretKeyRef == nil) != (tt.want.SecretKeyRef == nil) ||
+				(got.SecretKeyRef != nil && *got.SecretKeyRef != *tt.want.SecretKeyRef) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+
+			// Encoding and decoding again gives the same value
+			data, err := yaml.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var again SecretValue
+			if err := yaml.Unmarshal(data, &again); err != nil {
+				t.Fatalf("Unmarshal(%q) error = %v", data, err)
+			}
+			if again.Value != got.Value || (again.SecretKeyRef == nil) != (got.SecretKeyRef == nil) {
+				t.Errorf("round trip = %+v, want %+v", again, got)
+			}
+		})
+	}
+}
+
+func TestSecretValueYAMLErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: "secretKeyref: {name: postgres, key: password}\n", want: `unknown secret key "secretKeyref"`},
+		{data: "[a, b]\n", want: "a secret must be a string or a mapping"},
+	}
+
+	for _, tt := range tests {
+		var got SecretValue
+		err := yaml.Unmarshal([]byte(tt.data), &got)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Unmarshal(%q) error = %v, want it to contain %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestSecretValueMarshalsLiteralsAsStrings(t *testing.T) {
+	data, err := yaml.Marshal(struct {
+		Password SecretValue `yaml:"password"`
+		Token    SecretValue `yaml:"token,omitempty"`
+	}{Password: SecretValue{Value: "s3cret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "password: s3cret\n"; string(data) != want {
+		t.Errorf("Marshal() = %q, want %q", data, want)
+	}
+}
+
+func TestErrorsSecret(t *testing.T) {
+	ref := &SecretKeyRef{Name: "postgres", Key: "password"}
+	tests := []struct {
+		name     string
+		secret   SecretValue
+		required bool
+		want     string
+	}{
+		{name: "literal", secret: SecretValue{Value: "s3cret"}, required: true},
+		{name: "reference", secret: SecretValue{SecretKeyRef: ref}, required: true},
+		{name: "optional and unset", secret: SecretValue{}},
+		{name: "required and unset", secret: SecretValue{}, required: true, want: "Password: is required"},
+		{name: "both", secret: SecretValue{Value: "s3cret", SecretKeyRef: ref}, want: "Password: must set either a literal value or secretKeyRef, not both"},
+		{name: "reference without key", secret: SecretValue{SecretKeyRef: &SecretKeyRef{Name: "postgres"}}, want: "Password.SecretKeyRef.Key: is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs Errors
+			errs.Secret("Password", tt.secret, tt.required)
+			if got := errs.Error(); got != tt.want {
+				t.Errorf("Secret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// secretTestValues holds the configs with secrets, leaving out the sections
+// whose validation is unrelated
+type secretTestValues struct {
+	Database PostgreSQLConfig `yaml:"database"`
+	Redis    RedisConfig      `yaml:"redis"`
+	Auth     AuthConfig       `yaml:"auth"`
+}
+
+func (v *secretTestValues) Validate() error {
+	var errs Errors
+	errs.Nested("Database", v.Database.Validate())
+	errs.Nested("Redis", v.Redis.Validate())
+	errs.Nested("Auth", v.Auth.Validate())
+	return errs.Err()
+}
+
+func TestSecretValidationReport(t *testing.T) {
+	doc := mustParseDocument(t, "values.yaml", `database:
+  host: db.example.com
+  port: 5432
+  database: portal
+  username: portal
+  password:
+    value: s3cret
+    secretKeyRef: {name: postgres, key: password}
+redis:
+  host: redis.example.com
+  port: 6379
+  password:
+    secretKeyRef: {name: redis}
+auth:
+  enabled: true
+  oauthClientId: portal
+  oauthClientSecret:
+    secretKeyRef: {name: oauth, key: client-secret}
+  oauthAuthUrl: https://auth.example.com/authorize
+  oauthTokenUrl: https://auth.example.com/token
+  oauthCallbackUrl: https://portal.example.com/callback
+  sessionSecret: too-short
+  sessionName: portal
+  sessionTtl: 24h
+`)
+
+	var values secretTestValues
+	report, err := validateDocuments(&values, []*Document{doc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"values.yaml:6:3: database.password: must set either a literal value or secretKeyRef, not both",
+		"values.yaml:13:5: redis.password.secretKeyRef.key: is required",
+		"values.yaml:22:3: auth.sessionSecret: must be at least 32 characters long",
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestStrictSecrets(t *testing.T) {
+	doc := mustParseDocument(t, "values.yaml", `database:
+  host: db.example.com
+  port: 5432
+  database: portal
+  username: portal
+  password:
+    secretKeyRef: {name: postgres, key: password}
+redis:
+  host: redis.example.com
+  port: 6379
+  password: s3cret
+auth:
+  enabled: true
+  oauthClientId: portal
+  oauthClientSecret:
+    secretKeyRef: {name: oauth, key: client-secret}
+  oauthAuthUrl: https://auth.example.com/authorize
+  oauthTokenUrl: https://auth.example.com/token
+  oauthCallbackUrl: https://portal.example.com/callback
+  sessionSecret: 0123456789abcdef0123456789abcdef
+  sessionName: portal
+  sessionTtl: 24h
+`)
+
+	var values secretTestValues
+	report, err := validateDocuments(&values, []*Document{doc}, StrictSecrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"values.yaml:11:3: redis.password: must be a secretKeyRef; literal secrets are not allowed",
+		"values.yaml:20:3: auth.sessionSecret: must be a secretKeyRef; literal secrets are not allowed",
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
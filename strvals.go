@@ -0,0 +1,374 @@
+package helmcharts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetKind selects how the values of a --set style flag are typed
+type SetKind string
+
+const (
+	// SetFlag infers booleans, integers and null like helm --set
+	SetFlag SetKind = "set"
+	// SetStringFlag keeps every value a string like helm --set-string
+	SetStringFlag SetKind = "set-string"
+	// SetJSONFlag parses every value as JSON like helm --set-json
+	SetJSONFlag SetKind = "set-json"
+)
+
+// maxSetIndex bounds list indices so that a typo cannot allocate a huge list
+const maxSetIndex = 65536
+
+// setExpr is a --set style flag carried by a Document
+type setExpr struct {
+	kind SetKind
+	expr string
+}
+
+// ParseSet parses the value of a --set, --set-string or --set-json flag, such
+// as "image.tag=1.2,ports[0].port=8080", into a document named after the flag.
+// Merged as an overlay, the flag is applied onto the values merged before it
+// the way Helm applies --set after -f files: list indices update the existing
+// list instead of replacing it.
+func ParseSet(kind SetKind, expr string) (*Document, error) {
+	doc := &Document{File: "--" + string(kind)}
+	if err := doc.applySet(setExpr{kind: kind, expr: expr}); err != nil {
+		return nil, fmt.Errorf("--%s %q: %w", kind, expr, err)
+	}
+	doc.sets = []setExpr{{kind: kind, expr: expr}}
+	return doc, nil
+}
+
+// applySet applies e onto the values tree of d, creating it when needed
+func (d *Document) applySet(e setExpr) error {
+	if d.Root == nil || len(d.Root.Content) == 0 {
+		d.Root = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := resolveAlias(d.Root.Content[0])
+	if root.Kind != yaml.MappingNode {
+		root = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		d.Root.Content[0] = root
+	}
+	p := &setParser{s: e.expr, kind: e.kind}
+	for p.pos < len(p.s) {
+		if err := p.key(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setParser implements Helm's strvals syntax on YAML nodes
+type setParser struct {
+	s    string
+	pos  int
+	kind SetKind
+}
+
+// key parses name(.name|[index])*=value into the mapping m
+func (p *setParser) key(m *yaml.Node) error {
+	k, stop := p.readUntil("=[,.")
+	switch stop {
+	case 0:
+		if k == "" {
+			return nil
+		}
+		return fmt.Errorf("key %q has no value", k)
+	case ',':
+		return fmt.Errorf("key %q has no value (cannot end with ,)", k)
+	}
+	if k == "" {
+		return fmt.Errorf("empty key before %q at offset %d", stop, p.pos-1)
+	}
+
+	switch stop {
+	case '=':
+		v, err := p.value()
+		if err != nil {
+			return fmt.Errorf("key %q: %w", k, err)
+		}
+		setMappingValue(m, k, v)
+		return nil
+	case '.':
+		child, err := childNode(m, k, yaml.MappingNode)
+		if err != nil {
+			return err
+		}
+		return p.key(child)
+	default: // '['
+		i, err := p.index()
+		if err != nil {
+			return fmt.Errorf("key %q: %w", k, err)
+		}
+		child, err := childNode(m, k, yaml.SequenceNode)
+		if err != nil {
+			return err
+		}
+		return p.listItem(child, i)
+	}
+}
+
+// listItem parses what follows list[i]: a value, a nested key or another index
+func (p *setParser) listItem(seq *yaml.Node, i int) error {
+	if p.pos >= len(p.s) {
+		return fmt.Errorf("list index %d has no value", i)
+	}
+	stop := p.s[p.pos]
+	p.pos++
+	switch stop {
+	case '=':
+		v, err := p.value()
+		if err != nil {
+			return fmt.Errorf("list index %d: %w", i, err)
+		}
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		*listElement(seq, i) = v
+		return nil
+	case '.':
+		elem, err := elementNode(seq, i, yaml.MappingNode)
+		if err != nil {
+			return err
+		}
+		return p.key(elem)
+	case '[':
+		j, err := p.index()
+		if err != nil {
+			return err
+		}
+		elem, err := elementNode(seq, i, yaml.SequenceNode)
+		if err != nil {
+			return err
+		}
+		return p.listItem(elem, j)
+	default:
+		return fmt.Errorf("unexpected %q after list index %d", stop, i)
+	}
+}
+
+// index parses the digits of a list index up to the closing bracket
+func (p *setParser) index() (int, error) {
+	end := strings.IndexByte(p.s[p.pos:], ']')
+	if end < 0 {
+		return 0, errors.New("list index is missing its closing ]")
+	}
+	raw := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	i, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid list index %q", raw)
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("negative list index %d", i)
+	}
+	if i > maxSetIndex {
+		return 0, fmt.Errorf("list index %d exceeds the maximum of %d", i, maxSetIndex)
+	}
+	return i, nil
+}
+
+// value parses the value after '=' and the ',' that ends it. A nil node
+// stands for null, which removes the key it is assigned to.
+func (p *setParser) value() (*yaml.Node, error) {
+	if p.kind == SetJSONFlag {
+		return p.jsonValue()
+	}
+	if p.pos < len(p.s) && p.s[p.pos] == '{' {
+		return p.list()
+	}
+	s, _ := p.readUntil(",")
+	return p.scalar(s), nil
+}
+
+// list parses the {a,b,c} list syntax
+func (p *setParser) list() (*yaml.Node, error) {
+	p.pos++ // {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	if p.pos < len(p.s) && p.s[p.pos] == '}' {
+		p.pos++
+		return seq, p.endValue()
+	}
+	for {
+		item, stop := p.readUntil(",}")
+		if stop == 0 {
+			return nil, errors.New("list is missing its closing }")
+		}
+		node := p.scalar(item)
+		if node == nil {
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		seq.Content = append(seq.Content, node)
+		if stop == '}' {
+			return seq, p.endValue()
+		}
+	}
+}
+
+// jsonValue parses a JSON document up to the ',' that ends it
+func (p *setParser) jsonValue() (*yaml.Node, error) {
+	dec := json.NewDecoder(strings.NewReader(p.s[p.pos:]))
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %w", err)
+	}
+	p.pos += int(dec.InputOffset())
+	if err := p.endValue(); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %w", err)
+	}
+	node := doc.Content[0]
+	if isNullNode(node) {
+		return nil, nil
+	}
+	return node, nil
+}
+
+// endValue consumes the ',' that separates a value from the next key
+func (p *setParser) endValue() error {
+	if p.pos >= len(p.s) {
+		return nil
+	}
+	if p.s[p.pos] != ',' {
+		return fmt.Errorf("unexpected %q after value", p.s[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// scalar types s as --set does, or keeps it a string for --set-string
+func (p *setParser) scalar(s string) *yaml.Node {
+	str := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+	if p.kind == SetStringFlag {
+		return str
+	}
+	switch {
+	case strings.EqualFold(s, "true"), strings.EqualFold(s, "false"):
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strings.ToLower(s)}
+	case strings.EqualFold(s, "null"):
+		return nil
+	case s == "0":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: s}
+	case s != "" && s[0] != '0':
+		// Like Helm, numbers with a leading zero stay strings
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(n, 10)}
+		}
+	}
+	return str
+}
+
+// readUntil reads up to the first unescaped byte of stops, which it consumes
+// and returns, or to the end of the input, returning 0. Backslashes escape the
+// next byte.
+func (p *setParser) readUntil(stops string) (string, byte) {
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		if c == '\\' && p.pos < len(p.s) {
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+			continue
+		}
+		if strings.IndexByte(stops, c) >= 0 {
+			return b.String(), c
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), 0
+}
+
+// setMappingValue stores v under key in m; a nil v removes the key
+func setMappingValue(m *yaml.Node, key string, v *yaml.Node) {
+	i := mappingIndex(m, key)
+	switch {
+	case v == nil && i >= 0:
+		m.Content = append(m.Content[:i], m.Content[i+2:]...)
+	case v == nil:
+	case i >= 0:
+		m.Content[i+1] = v
+	default:
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	}
+}
+
+// childNode returns the node of the given kind stored under key in m, creating
+// it when key is missing or null. Another kind of node is an error, as a path
+// cannot descend into a scalar, nor index a map.
+func childNode(m *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	i := mappingIndex(m, key)
+	if i < 0 {
+		child := newCollection(kind)
+		setMappingValue(m, key, child)
+		return child, nil
+	}
+	child, err := collectionNode(m.Content[i+1], kind)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", key, err)
+	}
+	m.Content[i+1] = child
+	return child, nil
+}
+
+// elementNode returns the node of the given kind stored at index i of seq,
+// creating it when the element is missing or null
+func elementNode(seq *yaml.Node, i int, kind yaml.Kind) (*yaml.Node, error) {
+	elem := listElement(seq, i)
+	child, err := collectionNode(*elem, kind)
+	if err != nil {
+		return nil, fmt.Errorf("list index %d: %w", i, err)
+	}
+	*elem = child
+	return child, nil
+}
+
+// collectionNode returns n when it is of the given kind, a new collection when
+// n is null, and an error otherwise
+func collectionNode(n *yaml.Node, kind yaml.Kind) (*yaml.Node, error) {
+	if isNullNode(n) {
+		return newCollection(kind), nil
+	}
+	n = resolveAlias(n)
+	if n.Kind != kind {
+		return nil, fmt.Errorf("is a %s, not a %s", kindName(n.Kind), kindName(kind))
+	}
+	return n, nil
+}
+
+// kindName names the kind of a node in errors
+func kindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "map"
+	case yaml.SequenceNode:
+		return "list"
+	default:
+		return "scalar"
+	}
+}
+
+// listElement returns the slot at index i of seq, growing it with nulls
+func listElement(seq *yaml.Node, i int) **yaml.Node {
+	for len(seq.Content) <= i {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+	}
+	return &seq.Content[i]
+}
+
+func newCollection(kind yaml.Kind) *yaml.Node {
+	if kind == yaml.SequenceNode {
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
@@ -0,0 +1,216 @@
+package helmcharts
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseSet(t *testing.T) {
+	tests := []struct {
+		name string
+		kind SetKind
+		expr string
+		want string
+	}{
+		{
+			name: "nested keys",
+			kind: SetFlag,
+			expr: "service.type=NodePort,service.port=8080",
+			want: "service:\n    type: NodePort\n    port: 8080\n",
+		},
+		{
+			name: "type inference",
+			kind: SetFlag,
+			expr: "a=true,b=FALSE,c=0,d=42,e=007,f=1.5,g=",
+			want: "a: true\nb: false\nc: 0\nd: 42\ne: \"007\"\nf: \"1.5\"\ng: \"\"\n",
+		},
+		{
+			name: "set-string keeps strings",
+			kind: SetStringFlag,
+			expr: "a=true,d=42,n=null",
+			want: "a: \"true\"\nd: \"42\"\nn: \"null\"\n",
+		},
+		{
+			name: "list indices",
+			kind: SetFlag,
+			expr: "ports[1].port=443,ports[1].name=https,matrix[0][1]=x",
+			want: "ports:\n    - null\n    - port: 443\n      name: https\nmatrix:\n    - - null\n      - x\n",
+		},
+		{
+			name: "list values",
+			kind: SetFlag,
+			expr: "hosts={a.example.com,b.example.com},empty={},next=1",
+			want: "hosts:\n    - a.example.com\n    - b.example.com\nempty: []\nnext: 1\n",
+		},
+		{
+			name: "escaped separators",
+			kind: SetFlag,
+			expr: `annotations.nginx\.ingress\.kubernetes\.io/rewrite=/,args=a\,b`,
+			want: "annotations:\n    nginx.ingress.kubernetes.io/rewrite: /\nargs: a,b\n",
+		},
+		{
+			name: "null removes a key",
+			kind: SetFlag,
+			expr: "a=1,a=null",
+			want: "{}\n",
+		},
+		{
+			name: "json values",
+			kind: SetJSONFlag,
+			expr: `resources={"limits":{"cpu":"1","memory":"1Gi"}},ports[0]={"name":"http","port":80},debug=true`,
+			want: "resources: {\"limits\": {\"cpu\": \"1\", \"memory\": \"1Gi\"}}\nports:\n    - {\"name\": \"http\", \"port\": 80}\ndebug: true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ParseSet(tt.kind, tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSet() error = %v", err)
+			}
+			if want := "--" + string(tt.kind); doc.File != want {
+				t.Errorf("File = %q, want %q", doc.File, want)
+			}
+			got, err := yaml.Marshal(doc.Root.Content[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ParseSet() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSetErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		kind SetKind
+		expr string
+		want string
+	}{
+		{name: "missing value", kind: SetFlag, expr: "a.b", want: `key "b" has no value`},
+		{name: "trailing comma key", kind: SetFlag, expr: "a=1,b,c=2", want: `key "b" has no value (cannot end with ,)`},
+		{name: "empty key", kind: SetFlag, expr: "a..b=1", want: "empty key"},
+		{name: "unclosed index", kind: SetFlag, expr: "a[0=1", want: "missing its closing ]"},
+		{name: "negative index", kind: SetFlag, expr: "a[-1]=1", want: "negative list index -1"},
+		{name: "huge index", kind: SetFlag, expr: "a[1000000]=1", want: "exceeds the maximum"},
+		{name: "index without value", kind: SetFlag, expr: "a[0]", want: "list index 0 has no value"},
+		{name: "unclosed list", kind: SetFlag, expr: "a={x,y", want: "missing its closing }"},
+		{name: "invalid json", kind: SetJSONFlag, expr: "a={oops}", want: "invalid JSON value"},
+		{name: "json trailing data", kind: SetJSONFlag, expr: `a="x"y`, want: `unexpected 'y' after value`},
+		{name: "key into a scalar", kind: SetFlag, expr: "a=1,a.b=2", want: `key "a": is a scalar, not a map`},
+		{name: "index into a map", kind: SetFlag, expr: "a.b=1,a[0]=2", want: `key "a": is a map, not a list`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSet(tt.kind, tt.expr)
+			if err == nil {
+				t.Fatalf("ParseSet(%q) should fail", tt.expr)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeValuesAppliesSetFlags(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", `service:
+  type: ClusterIP
+  port: 80
+ingress:
+  enabled: false
+  hosts:
+    - host: app.example.com
+      paths: [{path: /, pathType: Prefix}]
+    - host: www.example.com
+      paths: [{path: /, pathType: Prefix}]
+`)
+	overlay := mustParseDocument(t, "prod.yaml", "service:\n  type: LoadBalancer\n")
+	set, err := ParseSet(SetFlag, "ingress.enabled=true,ingress.hosts[1].host=prod.example.com,service.port=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setString, err := ParseSet(SetStringFlag, "ingress.className=nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, report, err := MergeValues[reportTestValues](base, overlay, set, setString)
+	if err != nil {
+		t.Fatalf("MergeValues() error = %v", err)
+	}
+
+	// Unlike an overlay file, an index updates the list instead of replacing it
+	hosts := values.Ingress.Hosts
+	if len(hosts) != 2 || hosts[0].Host != "app.example.com" || hosts[1].Host != "prod.example.com" || len(hosts[1].Paths) != 1 {
+		t.Errorf("Ingress.Hosts = %+v", hosts)
+	}
+	if values.Service.Type != "LoadBalancer" || !values.Ingress.Enabled || values.Ingress.ClassName != "nginx" {
+		t.Errorf("merged values = %+v", values)
+	}
+
+	if len(report.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1:\n%s", len(report.Issues), report)
+	}
+	if got := report.Issues[0]; got.Path != "service.port" || got.File != "--set" || got.Tag != "min" {
+		t.Errorf("issue = %s (%s), want service.port in --set (min)", got, got.Tag)
+	}
+}
+
+func TestMergeDocumentsSetErrors(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", `image: nginx:1.27
+ports:
+  - name: http
+    port: 80
+resources: ~
+`)
+
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{name: "key into a scalar", expr: "image.tag=1.28", want: `--set "image.tag=1.28": key "image": is a scalar, not a map`},
+		{name: "index into a scalar", expr: "image[0]=nginx", want: `--set "image[0]=nginx": key "image": is a scalar, not a list`},
+		{name: "key into a list", expr: "ports.http=8080", want: `--set "ports.http=8080": key "ports": is a list, not a map`},
+		{name: "index into a list item", expr: "ports[0][1]=8080", want: `--set "ports[0][1]=8080": list index 0: is a map, not a list`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseSet(SetFlag, tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSet() error = %v", err)
+			}
+			if _, err := MergeDocuments(base, set); err == nil || err.Error() != tt.want {
+				t.Errorf("MergeDocuments() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+
+	// null is replaced like a missing key
+	set, err := ParseSet(SetFlag, "resources.limits.cpu=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeDocuments(base, set); err != nil {
+		t.Errorf("MergeDocuments() error = %v, want null to be replaced", err)
+	}
+}
+
+func TestMergeValuesReportsSetTypeErrors(t *testing.T) {
+	base := mustParseDocument(t, "values.yaml", "service:\n  port: 80\n")
+	set, err := ParseSet(SetStringFlag, "service.port=8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = MergeValues[reportTestValues](base, set)
+	if err == nil || !strings.HasPrefix(err.Error(), "--set-string:") {
+		t.Errorf("MergeValues() error = %v, want a --set-string decode error", err)
+	}
+}
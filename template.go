@@ -0,0 +1,184 @@
+package helmcharts
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// templateActionPattern matches a template action such as {{ .Values.baseDomain }}
+	templateActionPattern = regexp.MustCompile(`(\s*)\{\{(-?)\s*(.*?)\s*(-?)\}\}(\s*)`)
+
+	// valuesRefPattern matches a reference into the values tree
+	valuesRefPattern = regexp.MustCompile(`^\.Values((?:\.[A-Za-z_][A-Za-z0-9_]*)+)$`)
+)
+
+// ResolveTemplates replaces the {{ .Values.x.y }} references in the fields of v
+// tagged tpl:"true" with the values they point to in the document, as the chart
+// templates do when rendering those fields with tpl. The document is modified in
+// place; references that cannot be resolved are reported and left untouched.
+func (d *Document) ResolveTemplates(v any) *ValidationReport {
+	return resolveTemplates(d, v, []*Document{d})
+}
+
+// resolveTemplates resolves the references in doc, locating failures in the
+// last of sources that defines the failing value
+func resolveTemplates(doc *Document, v any, sources []*Document) *ValidationReport {
+	report := &ValidationReport{}
+	if doc.Root == nil || len(doc.Root.Content) == 0 {
+		return report
+	}
+	r := &templateResolver{sources: sources, root: doc.Root.Content[0], report: report}
+	r.walk(r.root, reflect.TypeOf(v), nil, false)
+	return report
+}
+
+// templateResolver resolves template references against one values tree
+type templateResolver struct {
+	sources []*Document
+	root    *yaml.Node
+	report  *ValidationReport
+}
+
+// walk visits node alongside t and resolves the scalars of templated fields
+func (r *templateResolver) walk(node *yaml.Node, t reflect.Type, path yamlPathSegments, templated bool) {
+	node = resolveAlias(node)
+	t = derefType(t)
+	if t == nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if templated && node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "{{") {
+			r.resolveNode(node, path)
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode || reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			f, ok := fields[node.Content[i].Value]
+			if !ok {
+				continue
+			}
+			keyPath := appendPath(path, pathSegment{name: node.Content[i].Value})
+			r.walk(node.Content[i+1], f.Type, keyPath, f.Tag.Get("tpl") == "true")
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			r.walk(item, t.Elem(), appendPath(path, pathSegment{index: i, item: true}), templated)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			seg := pathSegment{name: node.Content[i].Value, key: true}
+			r.walk(node.Content[i+1], t.Elem(), appendPath(path, seg), templated)
+		}
+	}
+}
+
+// resolveNode replaces the value of the scalar node, reporting failures at path
+func (r *templateResolver) resolveNode(node *yaml.Node, path yamlPathSegments) {
+	resolved, err := r.resolve(node.Value, []string{path.String()})
+	if err != nil {
+		issue := ValidationIssue{
+			Path:    path.String(),
+			Tag:     "template",
+			Value:   node.Value,
+			Message: err.Error(),
+		}
+		locateIssue(&issue, path, r.sources)
+		r.report.Issues = append(r.report.Issues, issue)
+		return
+	}
+	node.Value = resolved
+	node.Tag = "!!str"
+	node.Style = 0
+}
+
+// resolve renders the template actions of s. stack holds the paths being
+// resolved, so that values referring back to themselves are detected.
+func (r *templateResolver) resolve(s string, stack []string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, m := range templateActionPattern.FindAllStringSubmatchIndex(s, -1) {
+		leading, trimLeft := s[m[2]:m[3]], m[5] > m[4]
+		expr := s[m[6]:m[7]]
+		trimRight, trailing := m[9] > m[8], s[m[10]:m[11]]
+
+		value, err := r.lookup(expr, stack)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(s[last:m[0]])
+		if !trimLeft {
+			b.WriteString(leading)
+		}
+		b.WriteString(value)
+		if !trimRight {
+			b.WriteString(trailing)
+		}
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// lookup evaluates a single .Values reference
+func (r *templateResolver) lookup(expr string, stack []string) (string, error) {
+	m := valuesRefPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return "", fmt.Errorf("unsupported template expression {{ %s }}; only .Values references can be resolved", expr)
+	}
+	ref := strings.TrimPrefix(m[1], ".")
+
+	node := r.root
+	var walked []string
+	for _, key := range strings.Split(ref, ".") {
+		node = resolveAlias(node)
+		if node.Kind != yaml.MappingNode {
+			return "", fmt.Errorf("cannot resolve .Values.%s: %s is not a mapping", ref, strings.Join(walked, "."))
+		}
+		value := mappingValue(node, key)
+		if value == nil {
+			return "", fmt.Errorf("cannot resolve .Values.%s: key %q is not defined", ref, strings.Join(append(walked, key), "."))
+		}
+		walked = append(walked, key)
+		node = value
+	}
+
+	node = resolveAlias(node)
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return "", fmt.Errorf("cannot resolve .Values.%s: it is not a scalar value", ref)
+	}
+	if !strings.Contains(node.Value, "{{") {
+		return node.Value, nil
+	}
+	for i, path := range stack {
+		if path == ref {
+			cycle := append(append([]string{}, stack[i:]...), ref)
+			return "", fmt.Errorf("template reference cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return r.resolve(node.Value, append(stack, ref))
+}
+
+// mappingValue returns the value stored under key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(node, key); i >= 0 {
+		return node.Content[i+1]
+	}
+	return nil
+}
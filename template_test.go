@@ -0,0 +1,124 @@
+package helmcharts
+
+import (
+	"strings"
+	"testing"
+)
+
+type templateTestValues struct {
+	BaseDomain string            `yaml:"baseDomain"`
+	Subdomain  string            `yaml:"subdomain" tpl:"true"`
+	Host       string            `yaml:"host,omitempty" validate:"omitempty,fqdn" tpl:"true"`
+	Aliases    []string          `yaml:"aliases,omitempty" validate:"dive,fqdn" tpl:"true"`
+	Literal    string            `yaml:"literal,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+}
+
+func (v *templateTestValues) Validate() error {
+	return ValidateStruct(v)
+}
+
+func TestResolveTemplates(t *testing.T) {
+	data := []byte(`baseDomain: tacokumo.dev
+subdomain: "api.{{ .Values.baseDomain }}"
+host: "{{- .Values.subdomain -}}"
+aliases:
+  - "www.{{ .Values.baseDomain }}"
+  - "{{ .Values.labels.zone }}.{{ .Values.baseDomain }}"
+literal: "{{ .Values.baseDomain }}"
+labels:
+  zone: eu
+`)
+
+	var values templateTestValues
+	report, err := ValidateYAML("values.yaml", data, &values)
+	if err != nil {
+		t.Fatalf("ValidateYAML() error = %v", err)
+	}
+	if !report.Valid() {
+		t.Fatalf("ValidateYAML() issues:\n%s", report)
+	}
+
+	want := templateTestValues{
+		BaseDomain: "tacokumo.dev",
+		Subdomain:  "api.tacokumo.dev",
+		Host:       "api.tacokumo.dev",
+		Aliases:    []string{"www.tacokumo.dev", "eu.tacokumo.dev"},
+		// Fields that are not rendered with tpl keep the template text
+		Literal: "{{ .Values.baseDomain }}",
+	}
+	if values.Subdomain != want.Subdomain || values.Host != want.Host || values.Literal != want.Literal ||
+		strings.Join(values.Aliases, ",") != strings.Join(want.Aliases, ",") {
+		t.Errorf("values = %+v, want %+v", values, want)
+	}
+}
+
+func TestResolveTemplatesReportsFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantPath    string
+		wantLine    int
+		wantMessage string
+	}{
+		{
+			name:        "undefined key",
+			data:        "baseDomain: tacokumo.dev\nhost: \"proxy.{{ .Values.baseDomian }}\"\n",
+			wantPath:    "host",
+			wantLine:    2,
+			wantMessage: `cannot resolve .Values.baseDomian: key "baseDomian" is not defined`,
+		},
+		{
+			name:        "not a mapping",
+			data:        "baseDomain: tacokumo.dev\nhost: \"{{ .Values.baseDomain.name }}\"\n",
+			wantPath:    "host",
+			wantLine:    2,
+			wantMessage: "cannot resolve .Values.baseDomain.name: baseDomain is not a mapping",
+		},
+		{
+			name:        "not a scalar",
+			data:        "labels:\n  zone: eu\naliases:\n  - \"{{ .Values.labels }}\"\n",
+			wantPath:    "aliases[0]",
+			wantLine:    4,
+			wantMessage: "cannot resolve .Values.labels: it is not a scalar value",
+		},
+		{
+			name:        "cycle",
+			data:        "subdomain: \"{{ .Values.host }}\"\nhost: \"{{ .Values.subdomain }}\"\n",
+			wantPath:    "subdomain",
+			wantLine:    1,
+			wantMessage: "template reference cycle: subdomain -> host -> subdomain",
+		},
+		{
+			name:        "unsupported expression",
+			data:        "host: \"{{ include \\\"proxy.host\\\" . }}\"\n",
+			wantPath:    "host",
+			wantLine:    1,
+			wantMessage: `unsupported template expression {{ include "proxy.host" . }}; only .Values references can be resolved`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var values templateTestValues
+			report, err := ValidateYAML("values.yaml", []byte(tt.data), &values)
+			if err != nil {
+				t.Fatalf("ValidateYAML() error = %v", err)
+			}
+			if len(report.Issues) == 0 {
+				t.Fatal("ValidateYAML() reported no issues")
+			}
+
+			issue := report.Issues[0]
+			if issue.Tag != "template" || issue.Path != tt.wantPath || issue.Line != tt.wantLine || issue.Message != tt.wantMessage {
+				t.Errorf("issue = %+v, want template failure at %s (line %d): %s", issue, tt.wantPath, tt.wantLine, tt.wantMessage)
+			}
+			// The unresolved value is not reported a second time by validation
+			for _, other := range report.Issues[1:] {
+				if other.Path == issue.Path {
+					t.Errorf("duplicate issue for %s: %s", issue.Path, other)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package helmcharts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultTLSExpiryWarning is how long before it expires a certificate is
+// reported as expiring when TLSInspectOptions.ExpiryWarning is not set
+const DefaultTLSExpiryWarning = 30 * 24 * time.Hour
+
+// TLSInspectOptions configures TLSConfig.Inspect
+type TLSInspectOptions struct {
+	// Now is when the certificate is checked, time.Now when zero
+	Now time.Time
+
+	// ExpiryWarning is how long before it expires the certificate is reported
+	// as expiring, DefaultTLSExpiryWarning when zero
+	ExpiryWarning time.Duration
+
+	// Hostnames must be covered by the subject alternative names of the
+	// certificate, e.g. IngressHostnames of the chart's values
+	Hostnames []string
+}
+
+// TLSMaterial is what TLSConfig.Inspect found in the files of the config
+type TLSMaterial struct {
+	// Certificate is the leaf certificate, the first one of CertFile
+	Certificate *x509.Certificate
+
+	// Intermediates are the other certificates of CertFile
+	Intermediates []*x509.Certificate
+
+	// Warnings are findings that do not fail the inspection, such as a
+	// certificate expiring within the warning window
+	Warnings Errors
+}
+
+// Inspect is an opt-in deep check of the config that reads its PEM files. It
+// validates the config and then checks that the key matches the certificate,
+// that the certificate is valid now and chains up to CAFile when set, and that
+// it covers the hostnames of opts. Failures are returned as Errors on CertFile,
+// KeyFile or CAFile; the expiry, chain and hostname findings of the
+// certificate are all reported on CertFile under their own tags. A disabled
+// config is not inspected and gives a nil material.
+func (t *TLSConfig) Inspect(opts TLSInspectOptions) (*TLSMaterial, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if !t.Enabled {
+		return nil, nil
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.ExpiryWarning == 0 {
+		opts.ExpiryWarning = DefaultTLSExpiryWarning
+	}
+
+	var errs Errors
+	certPEM, err := os.ReadFile(t.CertFile)
+	if err != nil {
+		errs.Add("CertFile", "tls_file", fmt.Sprintf("cannot be read: %v", err))
+	}
+	keyPEM, err := os.ReadFile(t.KeyFile)
+	if err != nil {
+		errs.Add("KeyFile", "tls_file", fmt.Sprintf("cannot be read: %v", err))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	certs, err := parseCertificates(certPEM)
+	if err != nil {
+		errs.Add("CertFile", "tls_certificate", err.Error())
+		return nil, errs
+	}
+	m := &TLSMaterial{Certificate: certs[0], Intermediates: certs[1:]}
+
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		msg := strings.TrimPrefix(err.Error(), "tls: ")
+		if strings.Contains(msg, "does not match") {
+			msg = "does not match the certificate of certFile"
+		}
+		errs.Add("KeyFile", "tls_key_pair", msg)
+	}
+
+	m.checkValidity(&errs, opts)
+	if t.CAFile != "" {
+		m.checkChain(&errs, t.CAFile, opts.Now)
+	}
+	m.checkHostnames(&errs, opts.Hostnames)
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseCertificates parses the PEM encoded certificates of data
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("has an invalid certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("has no PEM encoded certificate")
+	}
+	return certs, nil
+}
+
+// checkValidity fails certificates that are not yet or no longer valid and
+// warns about those expiring within the warning window
+func (m *TLSMaterial) checkValidity(errs *Errors, opts TLSInspectOptions) {
+	cert := m.Certificate
+	switch {
+	case opts.Now.Before(cert.NotBefore):
+		errs.Add("CertFile", "tls_expiry", fmt.Sprintf("certificate is not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339)))
+	case opts.Now.After(cert.NotAfter):
+		errs.Add("CertFile", "tls_expiry", fmt.Sprintf("certificate expired on %s", cert.NotAfter.UTC().Format(time.RFC3339)))
+	case cert.NotAfter.Sub(opts.Now) < opts.ExpiryWarning:
+		days := int(cert.NotAfter.Sub(opts.Now).Hours() / 24)
+		m.Warnings.Add("CertFile", "tls_expiry", fmt.Sprintf("certificate expires in %d days, on %s", days, cert.NotAfter.UTC().Format(time.RFC3339)))
+	}
+}
+
+// checkChain verifies that the certificate chains up to a certificate of caFile
+func (m *TLSMaterial) checkChain(errs *Errors, caFile string, now time.Time) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		errs.Add("CAFile", "tls_file", fmt.Sprintf("cannot be read: %v", err))
+		return
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		errs.Add("CAFile", "tls_certificate", "has no PEM encoded certificate")
+		return
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range m.Intermediates {
+		intermediates.AddCert(cert)
+	}
+	_, err = m.Certificate.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		errs.Add("CertFile", "tls_chain", fmt.Sprintf("certificate is not trusted by caFile: %v", err))
+	}
+}
+
+// checkHostnames fails hostnames that the certificate does not cover
+func (m *TLSMaterial) checkHostnames(errs *Errors, hostnames []string) {
+	var missing []string
+	for _, host := range hostnames {
+		if m.Certificate.VerifyHostname(host) != nil {
+			missing = append(missing, host)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	covered := slices.Clone(m.Certificate.DNSNames)
+	for _, ip := range m.Certificate.IPAddresses {
+		covered = append(covered, ip.String())
+	}
+	errs.Add("CertFile", "tls_hostname", fmt.Sprintf("certificate does not cover %s; it covers %s",
+		strings.Join(missing, ", "), strings.Join(covered, ", ")))
+}
+
+// HostnameRouter is implemented by the sections of a chart's values that route
+// traffic for host names, such as the ingress and HTTPRoute configs
+type HostnameRouter interface {
+	// RoutedHostnames returns the host names of the section, none when it is disabled
+	RoutedHostnames() []string
+}
+
+// IngressHostnames returns the sorted host names of every HostnameRouter of
+// values, a chart's *Values, such as the hosts and TLS hosts of its ingress
+// and the hostnames of its HTTPRoute. Resolve the templates of the values
+// first, as hosts tagged tpl:"true" are returned as they are.
+func IngressHostnames(values any) []string {
+	var hosts []string
+	walkValues("", reflect.ValueOf(values), "", func(_ string, v reflect.Value, _ reflect.StructTag) bool {
+		if !v.CanAddr() {
+			return false
+		}
+		router, ok := v.Addr().Interface().(HostnameRouter)
+		if !ok {
+			return false
+		}
+		hosts = append(hosts, router.RoutedHostnames()...)
+		return true
+	})
+	slices.Sort(hosts)
+	return slices.Compact(hosts)
+}
@@ -0,0 +1,285 @@
+package helmcharts
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tlsTestNow is the time the test certificates are inspected at
+var tlsTestNow = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// tlsTestCert is a generated certificate and its key
+type tlsTestCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTLSTestCert generates a certificate for template, signed by parent or
+// self-signed when parent is nil
+func newTLSTestCert(t *testing.T, template *x509.Certificate, parent *tlsTestCert) *tlsTestCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	if template.NotBefore.IsZero() {
+		template.NotBefore = tlsTestNow.Add(-24 * time.Hour)
+	}
+	if template.NotAfter.IsZero() {
+		template.NotAfter = tlsTestNow.Add(365 * 24 * time.Hour)
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tlsTestCert{cert: cert, key: key}
+}
+
+func newTLSTestCA(t *testing.T, name string) *tlsTestCert {
+	return newTLSTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+func newTLSTestLeaf(t *testing.T, ca *tlsTestCert, notAfter time.Time, hosts ...string) *tlsTestCert {
+	return newTLSTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0]},
+		DNSNames:    hosts,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+}
+
+// writeTLSTestFile writes the PEM encoding of certs and key to a file of dir
+func writeTLSTestFile(t *testing.T, dir, name string, key *ecdsa.PrivateKey, certs ...*x509.Certificate) string {
+	t.Helper()
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	if key != nil {
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})...)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTLSConfigInspect(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTLSTestCA(t, "tacokumo test CA")
+	otherCA := newTLSTestCA(t, "other CA")
+	leaf := newTLSTestLeaf(t, ca, time.Time{}, "portal.tacokumo.dev", "*.preview.tacokumo.dev")
+	expiring := newTLSTestLeaf(t, ca, tlsTestNow.Add(10*24*time.Hour), "portal.tacokumo.dev")
+	expired := newTLSTestLeaf(t, ca, tlsTestNow.Add(-time.Hour), "portal.tacokumo.dev")
+	other := newTLSTestLeaf(t, otherCA, time.Time{}, "portal.tacokumo.dev")
+
+	caFile := writeTLSTestFile(t, dir, "ca.crt", nil, ca.cert)
+	certFile := writeTLSTestFile(t, dir, "tls.crt", nil, leaf.cert)
+	keyFile := writeTLSTestFile(t, dir, "tls.key", leaf.key)
+	config := func(cert, key string) TLSConfig {
+		return TLSConfig{Enabled: true, CertFile: cert, KeyFile: key, CAFile: caFile}
+	}
+
+	tests := []struct {
+		name         string
+		config       TLSConfig
+		opts         TLSInspectOptions
+		want         []string
+		wantWarnings []string
+	}{
+		{
+			name:   "valid",
+			config: config(certFile, keyFile),
+			opts:   TLSInspectOptions{Hostnames: []string{"portal.tacokumo.dev", "pr-1.preview.tacokumo.dev"}},
+		},
+		{
+			name:   "disabled",
+			config: TLSConfig{CertFile: filepath.Join(dir, "missing.crt")},
+		},
+		{
+			name:   "key does not match",
+			config: config(certFile, writeTLSTestFile(t, dir, "other.key", other.key)),
+			want:   []string{"KeyFile: does not match the certificate of certFile"},
+		},
+		{
+			name:   "missing files",
+			config: config(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key")),
+			want:   []string{"CertFile: cannot be read", "KeyFile: cannot be read"},
+		},
+		{
+			name:   "key file without certificate",
+			config: config(keyFile, keyFile),
+			want:   []string{"CertFile: has no PEM encoded certificate"},
+		},
+		{
+			name:   "expired",
+			config: config(writeTLSTestFile(t, dir, "expired.crt", nil, expired.cert), writeTLSTestFile(t, dir, "expired.key", expired.key)),
+			want:   []string{"CertFile: certificate expired on 2026-05-31T23:00:00Z"},
+		},
+		{
+			name:         "expiring within the warning window",
+			config:       config(writeTLSTestFile(t, dir, "expiring.crt", nil, expiring.cert), writeTLSTestFile(t, dir, "expiring.key", expiring.key)),
+			wantWarnings: []string{"CertFile: certificate expires in 10 days, on 2026-06-11T00:00:00Z"},
+		},
+		{
+			name:   "expiring outside a shorter warning window",
+			config: config(writeTLSTestFile(t, dir, "expiring.crt", nil, expiring.cert), writeTLSTestFile(t, dir, "expiring.key", expiring.key)),
+			opts:   TLSInspectOptions{ExpiryWarning: 7 * 24 * time.Hour},
+		},
+		{
+			name:   "not signed by the CA",
+			config: config(writeTLSTestFile(t, dir, "other.crt", nil, other.cert), writeTLSTestFile(t, dir, "other.key", other.key)),
+			want:   []string{"CertFile: certificate is not trusted by caFile"},
+		},
+		{
+			name:   "host names not covered",
+			config: config(certFile, keyFile),
+			opts:   TLSInspectOptions{Hostnames: []string{"portal.tacokumo.dev", "admin.tacokumo.dev", "a.b.preview.tacokumo.dev"}},
+			want:   []string{"CertFile: certificate does not cover admin.tacokumo.dev, a.b.preview.tacokumo.dev; it covers portal.tacokumo.dev, *.preview.tacokumo.dev"},
+		},
+		{
+			name:   "expired and host names not covered",
+			config: config(writeTLSTestFile(t, dir, "expired.crt", nil, expired.cert), writeTLSTestFile(t, dir, "expired.key", expired.key)),
+			opts:   TLSInspectOptions{Hostnames: []string{"admin.tacokumo.dev"}},
+			want: []string{
+				"CertFile: certificate expired on 2026-05-31T23:00:00Z",
+				"CertFile: certificate does not cover admin.tacokumo.dev",
+			},
+		},
+		{
+			name:   "not signed by the CA and host names not covered",
+			config: config(writeTLSTestFile(t, dir, "other.crt", nil, other.cert), writeTLSTestFile(t, dir, "other.key", other.key)),
+			opts:   TLSInspectOptions{Hostnames: []string{"admin.tacokumo.dev"}},
+			want: []string{
+				"CertFile: certificate is not trusted by caFile",
+				"CertFile: certificate does not cover admin.tacokumo.dev",
+			},
+		},
+		{
+			name:   "versions out of order",
+			config: TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3", MaxVersion: "1.2"},
+			want:   []string{`MinVersion: must not be greater than maxVersion 1.2 (got "1.3")`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.Now.IsZero() {
+				tt.opts.Now = tlsTestNow
+			}
+			m, err := tt.config.Inspect(tt.opts)
+			if len(tt.want) > 0 {
+				if err == nil {
+					t.Fatalf("Inspect() error = nil, want %q", tt.want)
+				}
+				for _, want := range tt.want {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Inspect() error = %v, want it to contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Inspect() error = %v", err)
+			}
+			if !tt.config.Enabled {
+				if m != nil {
+					t.Errorf("Inspect() = %+v, want nil for a disabled config", m)
+				}
+				return
+			}
+			var warnings []string
+			for _, w := range m.Warnings {
+				warnings = append(warnings, w.Error())
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("Inspect() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestTLSConfigInspectIntermediates(t *testing.T) {
+	dir := t.TempDir()
+	root := newTLSTestCA(t, "root")
+	intermediate := newTLSTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "intermediate"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root)
+	leaf := newTLSTestLeaf(t, intermediate, time.Time{}, "portal.tacokumo.dev")
+
+	config := TLSConfig{
+		Enabled:  true,
+		CertFile: writeTLSTestFile(t, dir, "tls.crt", nil, leaf.cert, intermediate.cert),
+		KeyFile:  writeTLSTestFile(t, dir, "tls.key", leaf.key),
+		CAFile:   writeTLSTestFile(t, dir, "ca.crt", nil, root.cert),
+	}
+	m, err := config.Inspect(TLSInspectOptions{Now: tlsTestNow})
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if m.Certificate.Subject.CommonName != "portal.tacokumo.dev" || len(m.Intermediates) != 1 {
+		t.Errorf("Inspect() = %s with %d intermediates, want the leaf and one intermediate", m.Certificate.Subject, len(m.Intermediates))
+	}
+}
+
+func TestTLSConfigInspectReportsOnCertFile(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTLSTestCA(t, "ca")
+	expired := newTLSTestLeaf(t, ca, tlsTestNow.Add(-time.Hour), "portal.tacokumo.dev")
+	values := struct {
+		TLS TLSConfig `yaml:"tls"`
+	}{TLS: TLSConfig{
+		Enabled:  true,
+		CertFile: writeTLSTestFile(t, dir, "tls.crt", nil, expired.cert),
+		KeyFile:  writeTLSTestFile(t, dir, "tls.key", expired.key),
+	}}
+
+	_, err := values.TLS.Inspect(TLSInspectOptions{Now: tlsTestNow, Hostnames: []string{"admin.tacokumo.dev"}})
+	var errs Errors
+	errs.Nested("TLS", err)
+
+	// Both findings are reported, on the certFile key of the values
+	report := NewValidationReport(&values, errs.Err())
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.Path+" "+issue.Tag)
+	}
+	want := []string{"tls.certFile tls_expiry", "tls.certFile tls_hostname"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report issues = %q, want %q", got, want)
+	}
+}
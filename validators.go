@@ -2,9 +2,12 @@ package helmcharts
 
 import (
 	"fmt"
+	"net"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -32,23 +35,38 @@ func RegisterCustomValidators(v *validator.Validate) error {
 		return err
 	}
 
+	// Container image reference validator (nginx:1.27, ghcr.io/org/app@sha256:...)
+	if err := v.RegisterValidation("image_reference", validateImageReference); err != nil {
+		return err
+	}
+
+	// Network address validator (redis-0.redis:6379, 10.0.0.1:6379, [::1]:6379)
+	if err := v.RegisterValidation("host_port", validateHostPort); err != nil {
+		return err
+	}
+
+	// HTTP token validator (RFC 7230 header names such as X-Request-Id)
+	if err := v.RegisterValidation("http_token", validateHTTPToken); err != nil {
+		return err
+	}
+
+	// CORS origin validator (*, https://portal.tacokumo.dev, https://*.tacokumo.dev)
+	if err := v.RegisterValidation("cors_origin", validateCORSOrigin); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-// validateResourceQuantity validates Kubernetes resource quantities
+// validateResourceQuantity validates Kubernetes resource quantities, which
+// must follow the ParseQuantity grammar and must not be negative
 func validateResourceQuantity(fl validator.FieldLevel) bool {
 	quantity := fl.Field().String()
 	if quantity == "" {
 		return true // Allow empty values for omitempty
 	}
 
-	// CPU resource patterns: 100m, 0.1, 1
-	cpuPattern := regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)([m]?)$`)
-
-	// Memory resource patterns: 128Mi, 1Gi, 512Ki, 1000000000 (bytes)
-	memoryPattern := regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)([KMGTPE]i?|[kmgtpe])?$`)
-
-	return cpuPattern.MatchString(quantity) || memoryPattern.MatchString(quantity)
+	return validQuantity(quantity)
 }
 
 // validateDuration validates Go duration strings
@@ -89,6 +107,76 @@ func validatePortString(fl validator.FieldLevel) bool {
 	return port >= 1 && port <= 65535
 }
 
+// validateImageReference validates container image references
+func validateImageReference(fl validator.FieldLevel) bool {
+	ref := fl.Field().String()
+	if ref == "" {
+		return true // Allow empty values for omitempty
+	}
+
+	_, err := ParseImageReference(ref)
+	return err == nil
+}
+
+// hostnamePattern matches an RFC 1123 host name such as redis-0.redis.svc
+var hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(\.([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*$`)
+
+// validateHostPort validates host:port addresses, with IPv6 hosts in brackets
+func validateHostPort(fl validator.FieldLevel) bool {
+	addr := fl.Field().String()
+	if addr == "" {
+		return true // Allow empty values for omitempty
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return false
+	}
+	return net.ParseIP(host) != nil || hostnamePattern.MatchString(host)
+}
+
+// validateHTTPToken validates RFC 7230 tokens, which header names must be
+func validateHTTPToken(fl validator.FieldLevel) bool {
+	token := fl.Field().String()
+	if token == "" {
+		return true // Allow empty values for omitempty
+	}
+
+	return isHTTPToken(token)
+}
+
+// isHTTPToken reports whether s is a non-empty RFC 7230 token: letters, digits
+// and !#$%&'*+-.^_`|~
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateCORSOrigin validates the allowed origins of a CORS policy
+func validateCORSOrigin(fl validator.FieldLevel) bool {
+	origin := fl.Field().String()
+	if origin == "" {
+		return true // Allow empty values for omitempty
+	}
+
+	_, err := ParseCORSOrigin(origin)
+	return err == nil
+}
+
 // GetValidatorWithCustomValidations returns a validator instance with all custom validations registered
 func GetValidatorWithCustomValidations() (*validator.Validate, error) {
 	v := validator.New()
@@ -100,9 +188,13 @@ func GetValidatorWithCustomValidations() (*validator.Validate, error) {
 	return v, nil
 }
 
+// DefaultValidator returns the process-wide validator with all custom validations registered.
+// The validator caches parsed struct tags per type, so it is built only once and shared.
+var DefaultValidator = sync.OnceValues(GetValidatorWithCustomValidations)
+
 // ValidateStruct validates a struct using the custom validator
 func ValidateStruct(s interface{}) error {
-	v, err := GetValidatorWithCustomValidations()
+	v, err := DefaultValidator()
 	if err != nil {
 		return err
 	}
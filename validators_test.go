@@ -0,0 +1,165 @@
+package helmcharts
+
+import (
+	"testing"
+)
+
+func TestDefaultValidatorIsShared(t *testing.T) {
+	first, err := DefaultValidator()
+	if err != nil {
+		t.Fatalf("DefaultValidator() error = %v", err)
+	}
+	second, err := DefaultValidator()
+	if err != nil {
+		t.Fatalf("DefaultValidator() error = %v", err)
+	}
+	if first != second {
+		t.Error("DefaultValidator() should return the same instance on every call")
+	}
+}
+
+func TestValidateUsesCustomValidations(t *testing.T) {
+	// These used to panic with "Undefined validation function" because the
+	// custom tags were not registered on the validator used by Validate()
+	tests := []struct {
+		name    string
+		value   Validatable
+		wantErr bool
+	}{
+		{
+			name:    "valid resources",
+			value:   &Resources{Requests: ResourceRequests{CPU: "100m", Memory: "128Mi"}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid resources",
+			value:   &Resources{Requests: ResourceRequests{CPU: "invalid"}},
+			wantErr: true,
+		},
+		{
+			name:    "valid redis",
+			value:   &RedisConfig{Host: "redis.example.com", Port: 6379, DialTimeout: "5s"},
+			wantErr: false,
+		},
+		{
+			name:    "invalid redis duration",
+			value:   &RedisConfig{Host: "redis.example.com", Port: 6379, DialTimeout: "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "valid TLS",
+			value:   &TLSConfig{Enabled: true, CertFile: "/etc/tls/tls.crt", KeyFile: "/etc/tls/tls.key"},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.value.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStructDoesNotReparseTags(t *testing.T) {
+	config := benchmarkValuesDocument()
+
+	shared := testing.AllocsPerRun(100, func() {
+		_ = ValidateStruct(&config)
+	})
+	fresh := testing.AllocsPerRun(100, func() {
+		v, _ := GetValidatorWithCustomValidations()
+		_ = v.Struct(&config)
+	})
+
+	// Building a validator and parsing the struct tags dominates the fresh case
+	if shared*10 > fresh {
+		t.Errorf("ValidateStruct allocated %.0f times per call, fresh validator %.0f; tags appear to be re-parsed", shared, fresh)
+	}
+}
+
+func BenchmarkValidateStruct(b *testing.B) {
+	configs := make([]benchmarkValues, 5000)
+	for i := range configs {
+		configs[i] = benchmarkValuesDocument()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range configs {
+			if err := ValidateStruct(&configs[j]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkValidateStructFreshValidator(b *testing.B) {
+	config := benchmarkValuesDocument()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v, err := GetValidatorWithCustomValidations()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := v.Struct(&config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkValues resembles a chart values document
+type benchmarkValues struct {
+	Image     Image            `yaml:"image"`
+	Resources Resources        `yaml:"resources"`
+	Service   Service          `yaml:"service"`
+	Ingress   Ingress          `yaml:"ingress"`
+	Probe     HTTPProbe        `yaml:"probe"`
+	Database  PostgreSQLConfig `yaml:"database"`
+	Redis     RedisConfig      `yaml:"redis"`
+}
+
+func benchmarkValuesDocument() benchmarkValues {
+	return benchmarkValues{
+		Image: Image{Repository: "ghcr.io/tacokumo/portal-api", Tag: "v1.0.0"},
+		Resources: Resources{
+			Requests: ResourceRequests{CPU: "100m", Memory: "128Mi"},
+			Limits:   ResourceLimits{CPU: "500m", Memory: "512Mi"},
+		},
+		Service: Service{Type: "ClusterIP", Port: 80},
+		Ingress: Ingress{
+			Enabled:   true,
+			ClassName: "nginx",
+			Hosts: []IngressHost{
+				{Host: "portal.example.com", Paths: []IngressPath{{Path: "/", PathType: "Prefix"}}},
+			},
+		},
+		Probe: HTTPProbe{
+			Enabled:          true,
+			Path:             "/healthz",
+			Port:             8080,
+			PeriodSeconds:    10,
+			TimeoutSeconds:   1,
+			FailureThreshold: 3,
+		},
+		Database: PostgreSQLConfig{
+			Host:            "db.example.com",
+			Port:            5432,
+			Database:        "portal",
+			Username:        "portal",
+			Password:        SecretValue{Value: "secret"},
+			SSLMode:         "require",
+			ConnMaxLifetime: "1h",
+		},
+		Redis: RedisConfig{
+			Host:        "redis.example.com",
+			Port:        6379,
+			DialTimeout: "5s",
+		},
+	}
+}